@@ -2,10 +2,12 @@ package integration
 
 import (
 	"log"
+	"os"
 	"testing"
 
 	"github.com/AdanJSuarez/form3/pkg/account"
 	"github.com/AdanJSuarez/form3/pkg/form3"
+	"github.com/AdanJSuarez/form3/pkg/form3test"
 	"github.com/AdanJSuarez/form3/pkg/model"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
@@ -13,12 +15,14 @@ import (
 
 const (
 	organizationID = "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
-	baseAPIURL     = "http://accountapi:8080"
 	accountPath    = "/v1/organisation/accounts"
 	fakeIBAN       = "ES2317002001280000001200527600"
+	baseURLEnvKey  = "BASE_URL"
+	pathEnvKey     = "ACCOUNT_PATH"
 )
 
 var (
+	baseAPIURL    = "http://accountapi:8080"
 	f3Test        *form3.Form3
 	accountTest   *account.Account
 	uuids         = make(map[string]struct{})
@@ -58,15 +62,38 @@ var (
 	}
 )
 
-type TSIntegration struct{ suite.Suite }
+type TSIntegration struct {
+	suite.Suite
+	fakeAPI *form3test.Server
+}
 
 func TestRunTSIntegration(t *testing.T) {
 	suite.Run(t, new(TSIntegration))
 }
 
+// SetupSuite runs the suite against the in-memory fake API when BASE_URL is not
+// set, as it is by docker-compose for the account API.
+func (ts *TSIntegration) SetupSuite() {
+	if rawBaseURL, ok := os.LookupEnv(baseURLEnvKey); ok {
+		baseAPIURL = rawBaseURL
+		return
+	}
+
+	ts.fakeAPI = form3test.NewServer()
+	baseAPIURL = ts.fakeAPI.URL
+	ts.T().Setenv(baseURLEnvKey, baseAPIURL)
+	ts.T().Setenv(pathEnvKey, form3test.AccountPath)
+}
+
+func (ts *TSIntegration) TearDownSuite() {
+	if ts.fakeAPI != nil {
+		ts.fakeAPI.Close()
+	}
+}
+
 func (ts *TSIntegration) BeforeTest(_, _ string) {
 	dataModelTest = dataModelUK
-	f3Test = form3.New()
+	f3Test, _ = form3.New()
 	if err := f3Test.ConfigurationByEnv(); err != nil {
 		log.Printf("Error in Configuration: %v", err)
 		return
@@ -84,7 +111,7 @@ func (ts *TSIntegration) AfterTest(_, _ string) {
 
 // It should connect, and return an error because of the wrong account "ID".
 func (ts *TSIntegration) TestConfigurationByValue() {
-	f3Test = form3.New()
+	f3Test, _ = form3.New()
 	if err := f3Test.ConfigurationByValue(baseAPIURL, accountPath); err != nil {
 		log.Printf("Error on ConfigurationByValue: %v", err)
 		return
@@ -97,7 +124,7 @@ func (ts *TSIntegration) TestConfigurationByValue() {
 
 // It should connect but returns an error because of the wrong Account path.
 func (ts *TSIntegration) TestInvalidConfigurationByValueWrongPath() {
-	f3Test = form3.New()
+	f3Test, _ = form3.New()
 	if err := f3Test.ConfigurationByValue(baseAPIURL, "/organisation/account"); err != nil {
 		log.Printf("Error on ConfigurationByValue: %v", err)
 		return
@@ -596,6 +623,47 @@ func (ts *TSIntegration) TestFetchExistingAccount() {
 	ts.Equal(dataModelTest, data)
 }
 
+// It should list every existing account, page by page
+func (ts *TSIntegration) TestListExistingAccounts() {
+	dataModelTest = dataModelBE
+	created := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		dataModelTest.Data.ID = generateAccountUUID()
+		_, err := accountTest.Create(dataModelTest)
+		ts.NoError(err)
+		created[dataModelTest.Data.ID] = false
+	}
+
+	iterator := accountTest.List(1)
+	for iterator.Next() {
+		if _, ok := created[iterator.Value().ID]; ok {
+			created[iterator.Value().ID] = true
+		}
+	}
+	ts.NoError(iterator.Err())
+	for id, found := range created {
+		ts.True(found, id)
+	}
+}
+
+// It should list only the accounts matching the filter
+func (ts *TSIntegration) TestListAccountsByFilter() {
+	dataModelTest = dataModelBE
+	dataModelTest.Data.ID = generateAccountUUID()
+	dataModelTest.Data.Attributes.AccountNumber = "7654321"
+	_, err := accountTest.Create(dataModelTest)
+	ts.NoError(err)
+
+	iterator := accountTest.ListByFilter(account.Filter{AccountNumber: "7654321"}, 10)
+	found := false
+	for iterator.Next() {
+		ts.Equal("7654321", iterator.Value().Attributes.AccountNumber)
+		found = found || iterator.Value().ID == dataModelTest.Data.ID
+	}
+	ts.NoError(iterator.Err())
+	ts.True(found, "the account created is not listed")
+}
+
 // It should delete an existing account
 func (ts *TSIntegration) TestDeleteExistingAccount() {
 	dataModelTest = dataModelBE
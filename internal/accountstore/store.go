@@ -0,0 +1,278 @@
+/*
+Package accountstore holds the accounts of the fake of the Form3 account API of
+the form3test package, following the rules of the API.
+*/
+package accountstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AdanJSuarez/form3/pkg/model"
+)
+
+const (
+	duplicateMessage      = "Account cannot be created as it violates a duplicate constraint"
+	notFoundMessageFmt    = "record %s does not exist"
+	invalidVersionMessage = "invalid version"
+	invalidIDMessage      = "id is not a valid uuid"
+	invalidFilterFmt      = "invalid filter: %s"
+
+	// InvalidBodyMessageFmt is the message of the requests with a body that cannot be decoded.
+	InvalidBodyMessageFmt = "invalid body: %v"
+)
+
+// Failure is an error of the API, with the status code of its response.
+type Failure struct {
+	StatusCode int
+	Message    string
+}
+
+func (f *Failure) Error() string {
+	return f.Message
+}
+
+// NewFailure returns the Failure with the status code and message.
+func NewFailure(statusCode int, message string) *Failure {
+	return &Failure{StatusCode: statusCode, Message: message}
+}
+
+// Account is an account with the timestamps the API keeps for it.
+type Account struct {
+	Data       model.Data
+	CreatedOn  time.Time
+	ModifiedOn time.Time
+}
+
+// filterableAttributes are the attributes the accounts can be listed by.
+var filterableAttributes = map[string]bool{
+	"bank_id":        true,
+	"bank_id_code":   true,
+	"account_number": true,
+	"iban":           true,
+	"country":        true,
+	"customer_id":    true,
+}
+
+// Store holds the accounts, in creation order. Its methods return a *Failure
+// when the API answers with an error. It is safe for concurrent use.
+type Store struct {
+	mu       sync.Mutex
+	accounts map[string]*Account
+	order    []string
+	now      func() time.Time
+}
+
+// New returns a Store without accounts.
+func New() *Store {
+	return &Store{
+		accounts: map[string]*Account{},
+		now:      time.Now,
+	}
+}
+
+// Create stores the account with version 0, whatever the version sent.
+func (s *Store) Create(data model.Data) (Account, error) {
+	if failures := validate(data); failures != "" {
+		return Account{}, NewFailure(http.StatusBadRequest, failures)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[data.ID]; ok {
+		return Account{}, NewFailure(http.StatusConflict, duplicateMessage)
+	}
+
+	data.Version = 0
+	now := s.now().UTC()
+	account := &Account{Data: data, CreatedOn: now, ModifiedOn: now}
+	s.accounts[data.ID] = account
+	s.order = append(s.order, data.ID)
+	return *account, nil
+}
+
+// Fetch returns the account with the ID.
+func (s *Store) Fetch(id string) (Account, error) {
+	if !uuidPattern.MatchString(id) {
+		return Account{}, NewFailure(http.StatusBadRequest, invalidIDMessage)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[id]
+	if !ok {
+		return Account{}, NewFailure(http.StatusNotFound, fmt.Sprintf(notFoundMessageFmt, id))
+	}
+	return *account, nil
+}
+
+// List returns the accounts of the page matching every filter, by the JSON name
+// of the attribute, and how many accounts match in total. It returns a 400
+// Failure if an attribute cannot be filtered by.
+func (s *Store) List(filters map[string]string, number, size int) ([]Account, int, error) {
+	for name := range filters {
+		if !filterableAttributes[name] {
+			return nil, 0, NewFailure(http.StatusBadRequest, fmt.Sprintf(invalidFilterFmt, name))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matching := []Account{}
+	for _, id := range s.order {
+		account := s.accounts[id]
+		if matches(account.Data.Attributes, filters) {
+			matching = append(matching, *account)
+		}
+	}
+
+	start := number * size
+	if start >= len(matching) {
+		return []Account{}, len(matching), nil
+	}
+	end := start + size
+	if end > len(matching) {
+		end = len(matching)
+	}
+	return matching[start:end], len(matching), nil
+}
+
+// Update sets over the attributes of the account the ones present in the JSON
+// object changes, if version is its current version, and increments the version.
+func (s *Store) Update(id string, version int64, changes json.RawMessage) (Account, error) {
+	if !uuidPattern.MatchString(id) {
+		return Account{}, NewFailure(http.StatusBadRequest, invalidIDMessage)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[id]
+	if !ok {
+		return Account{}, NewFailure(http.StatusNotFound, fmt.Sprintf(notFoundMessageFmt, id))
+	}
+	if account.Data.Version != version {
+		return Account{}, NewFailure(http.StatusConflict, invalidVersionMessage)
+	}
+
+	updated := account.Data
+	attributes, err := mergeAttributes(updated.Attributes, changes)
+	if err != nil {
+		return Account{}, NewFailure(http.StatusBadRequest, fmt.Sprintf(InvalidBodyMessageFmt, err))
+	}
+	updated.Attributes = attributes
+	if failures := validate(updated); failures != "" {
+		return Account{}, NewFailure(http.StatusBadRequest, failures)
+	}
+
+	updated.Version++
+	account.Data = updated
+	account.ModifiedOn = s.now().UTC()
+	return *account, nil
+}
+
+// Delete deletes the account with the ID, if version is its current version.
+func (s *Store) Delete(id string, version int64) error {
+	if !uuidPattern.MatchString(id) {
+		return NewFailure(http.StatusBadRequest, invalidIDMessage)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[id]
+	if !ok {
+		return NewFailure(http.StatusNotFound, fmt.Sprintf(notFoundMessageFmt, id))
+	}
+	if account.Data.Version != version {
+		return NewFailure(http.StatusConflict, invalidVersionMessage)
+	}
+
+	delete(s.accounts, id)
+	for i, orderedID := range s.order {
+		if orderedID == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// All returns the accounts, in creation order.
+func (s *Store) All() []model.Data {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts := make([]model.Data, 0, len(s.order))
+	for _, id := range s.order {
+		accounts = append(accounts, s.accounts[id].Data)
+	}
+	return accounts
+}
+
+// mergeAttributes returns the attributes with the keys of the JSON object changes
+// set over them.
+func mergeAttributes(attributes model.Attributes, changes json.RawMessage) (model.Attributes, error) {
+	if len(changes) == 0 {
+		return attributes, nil
+	}
+
+	current, err := attributesToMap(attributes)
+	if err != nil {
+		return attributes, err
+	}
+	changed := map[string]json.RawMessage{}
+	if err := json.Unmarshal(changes, &changed); err != nil {
+		return attributes, err
+	}
+	for key, value := range changed {
+		current[key] = value
+	}
+
+	merged, err := json.Marshal(current)
+	if err != nil {
+		return attributes, err
+	}
+	result := model.Attributes{}
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return attributes, err
+	}
+	return result, nil
+}
+
+// matches reports whether the attributes have the value of every filter.
+func matches(attributes model.Attributes, filters map[string]string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+
+	values, err := attributesToMap(attributes)
+	if err != nil {
+		return false
+	}
+	for name, expected := range filters {
+		var value string
+		if err := json.Unmarshal(values[name], &value); err != nil || value != expected {
+			return false
+		}
+	}
+	return true
+}
+
+func attributesToMap(attributes model.Attributes) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,163 @@
+package accountstore
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/AdanJSuarez/form3/pkg/model"
+)
+
+// Ref: https://www.api-docs.form3.tech/api/schemes/fps-direct/accounts/accounts/create-an-account
+
+const (
+	validationFailureList = "validation failure list:\n"
+	requiredFmt           = "%s in body is required"
+	patternFmt            = "%s in body should match '%s'"
+	enumFmt               = "%s in body should be one of %v"
+	uuidFmt               = "%s in body must be of type uuid: %q"
+	maxItemsFmt           = "%s in body should have at most %d items"
+	maxLengthFmt          = "%s in body should be at most %d chars long"
+	minLengthFmt          = "%s in body should be at least %d chars long"
+
+	maxNames       = 4
+	maxAltNames    = 3
+	maxNameLength  = 140
+	accountsType   = "accounts"
+	dataPath       = "data"
+	attributesPath = "data.attributes"
+)
+
+var (
+	uuidPattern           = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	accountClassification = []string{"Personal", "Business"}
+	accountStatus         = []string{"pending", "confirmed", "failed", "closed"}
+	accountTypes          = []string{accountsType}
+
+	// attributePatterns are the patterns of the optional attributes.
+	attributePatterns = []struct {
+		name    string
+		value   func(model.Attributes) string
+		pattern *regexp.Regexp
+	}{
+		{"base_currency", func(a model.Attributes) string { return a.BaseCurrency },
+			regexp.MustCompile(`^[A-Z]{3}$`)},
+		{"bank_id", func(a model.Attributes) string { return a.BankID },
+			regexp.MustCompile(`^[A-Z0-9]{0,16}$`)},
+		{"bank_id_code", func(a model.Attributes) string { return a.BankIDCode },
+			regexp.MustCompile(`^[A-Z]{0,16}$`)},
+		{"bic", func(a model.Attributes) string { return a.Bic },
+			regexp.MustCompile(`^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$`)},
+		{"account_number", func(a model.Attributes) string { return a.AccountNumber },
+			regexp.MustCompile(`^[A-Z0-9]{0,64}$`)},
+		{"iban", func(a model.Attributes) string { return a.Iban },
+			regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{0,64}$`)},
+	}
+	countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+/*
+validate returns the validation failures of the account, in the format of the
+API, or empty if it is valid:
+
+	validation failure list:
+	validation failure list:
+	data.id in body is required
+	data.attributes.country in body should match '^[A-Z]{2}$'
+*/
+func validate(data model.Data) string {
+	failures := validateData(data)
+	if reflect.ValueOf(data.Attributes).IsZero() {
+		failures = append(failures, fmt.Sprintf(requiredFmt, attributesPath))
+	} else {
+		failures = append(failures, validateAttributes(data.Attributes)...)
+	}
+
+	if len(failures) == 0 {
+		return ""
+	}
+	return validationFailureList + validationFailureList + strings.Join(failures, "\n")
+}
+
+func validateData(data model.Data) []string {
+	failures := []string{}
+	failures = appendUUIDFailure(failures, dataPath+".id", data.ID)
+	failures = appendUUIDFailure(failures, dataPath+".organisation_id", data.OrganizationID)
+	if data.Type == "" {
+		failures = append(failures, fmt.Sprintf(requiredFmt, dataPath+".type"))
+	} else if !oneOf(data.Type, accountTypes) {
+		failures = append(failures, fmt.Sprintf(enumFmt, dataPath+".type", accountTypes))
+	}
+	return failures
+}
+
+func validateAttributes(attributes model.Attributes) []string {
+	failures := []string{}
+	path := func(name string) string { return attributesPath + "." + name }
+
+	if attributes.Country == "" {
+		failures = append(failures, fmt.Sprintf(requiredFmt, path("country")))
+	} else if !countryPattern.MatchString(attributes.Country) {
+		failures = append(failures, fmt.Sprintf(patternFmt, path("country"), countryPattern))
+	}
+
+	for _, attribute := range attributePatterns {
+		value := attribute.value(attributes)
+		if value != "" && !attribute.pattern.MatchString(value) {
+			failures = append(failures, fmt.Sprintf(patternFmt, path(attribute.name), attribute.pattern))
+		}
+	}
+
+	if attributes.AccountClassification != "" && !oneOf(attributes.AccountClassification, accountClassification) {
+		failures = append(failures, fmt.Sprintf(enumFmt, path("account_classification"), accountClassification))
+	}
+	if attributes.Status != "" && !oneOf(attributes.Status, accountStatus) {
+		failures = append(failures, fmt.Sprintf(enumFmt, path("status"), accountStatus))
+	}
+
+	if len(attributes.Name) == 0 {
+		failures = append(failures, fmt.Sprintf(requiredFmt, path("name")))
+	}
+	failures = appendNamesFailures(failures, path("name"), attributes.Name, maxNames)
+	failures = appendNamesFailures(failures, path("alternative_names"), attributes.AlternativeNames, maxAltNames)
+
+	if len(attributes.SecondaryIdentification) > maxNameLength {
+		failures = append(failures, fmt.Sprintf(maxLengthFmt, path("secondary_identification"), maxNameLength))
+	}
+	return failures
+}
+
+func appendUUIDFailure(failures []string, path, value string) []string {
+	if value == "" {
+		return append(failures, fmt.Sprintf(requiredFmt, path))
+	}
+	if !uuidPattern.MatchString(value) {
+		return append(failures, fmt.Sprintf(uuidFmt, path, value))
+	}
+	return failures
+}
+
+func appendNamesFailures(failures []string, path string, names []string, maxItems int) []string {
+	if len(names) > maxItems {
+		failures = append(failures, fmt.Sprintf(maxItemsFmt, path, maxItems))
+	}
+	for i, name := range names {
+		itemPath := fmt.Sprintf("%s.%d", path, i)
+		if name == "" {
+			failures = append(failures, fmt.Sprintf(minLengthFmt, itemPath, 1))
+		} else if len(name) > maxNameLength {
+			failures = append(failures, fmt.Sprintf(maxLengthFmt, itemPath, maxNameLength))
+		}
+	}
+	return failures
+}
+
+func oneOf(value string, values []string) bool {
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
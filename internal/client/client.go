@@ -1,12 +1,15 @@
 package client
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 
 	"github.com/AdanJSuarez/form3/internal/client/httpclient"
 	"github.com/AdanJSuarez/form3/internal/client/request"
 	"github.com/AdanJSuarez/form3/internal/client/statuserrorhandler"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/retry"
 )
 
 type Client struct {
@@ -16,25 +19,59 @@ type Client struct {
 	statusErrorHandler statusErrorHandler
 }
 
-func New(clientURL url.URL) *Client {
+// Config holds the settings of the Client.
+type Config struct {
+	RetryPolicy retry.Policy
+	// StatusHandlers override the error returned for their status codes.
+	StatusHandlers map[int]apierror.StatusHandler
+	// CompressionThreshold is the minimum size of the request bodies compressed
+	// with gzip. Zero means the bodies are not compressed.
+	CompressionThreshold int
+	// Authenticator authenticates every request. Nil means no authentication.
+	Authenticator request.Authenticator
+	// HTTP holds the settings of the underlying http client.
+	HTTP httpclient.Settings
+}
+
+func New(clientURL url.URL, config Config) *Client {
 	return &Client{
 		clientURL:          clientURL,
-		httpClient:         httpclient.New(),
-		requestHandler:     request.NewRequestHandler(),
-		statusErrorHandler: statuserrorhandler.NewStatusErrorHandler(),
+		httpClient:         httpclient.New(config.RetryPolicy, config.Authenticator, config.HTTP),
+		requestHandler:     request.NewRequestHandler(config.CompressionThreshold),
+		statusErrorHandler: statuserrorhandler.NewStatusErrorHandler(config.StatusHandlers),
 	}
 }
 
-func (c *Client) Get(value string) (*http.Response, error) {
+func (c *Client) Get(ctx context.Context, value string) (*http.Response, error) {
 	url, err := c.joinValuesToURL(value)
 	if err != nil {
 		return nil, err
 	}
 
-	request, err := c.requestHandler.Request(nil, http.MethodGet, url, c.clientURL.Host)
+	request, err := c.requestHandler.Request(ctx, nil, http.MethodGet, url, c.clientURL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.httpClient.SendRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.statusOK(response) {
+		return c.statusErrorHandler.StatusError(response)
+	}
+
+	return response, nil
+}
+
+func (c *Client) List(ctx context.Context, query *request.Query) (*http.Response, error) {
+	request, err := c.requestHandler.Request(ctx, nil, http.MethodGet, c.clientURL.String(),
+		c.clientURL.Host)
 	if err != nil {
 		return nil, err
 	}
+	c.requestHandler.SetQuery(request, query)
 
 	response, err := c.httpClient.SendRequest(request)
 	if err != nil {
@@ -48,8 +85,8 @@ func (c *Client) Get(value string) (*http.Response, error) {
 	return response, nil
 }
 
-func (c *Client) Post(data interface{}) (*http.Response, error) {
-	request, err := c.requestHandler.Request(data, http.MethodPost, c.clientURL.String(),
+func (c *Client) Post(ctx context.Context, data interface{}) (*http.Response, error) {
+	request, err := c.requestHandler.Request(ctx, data, http.MethodPost, c.clientURL.String(),
 		c.clientURL.Host)
 	if err != nil {
 		return nil, err
@@ -67,17 +104,40 @@ func (c *Client) Post(data interface{}) (*http.Response, error) {
 	return response, nil
 }
 
-func (c *Client) Delete(value, parameterKey, parameterValue string) (*http.Response, error) {
+func (c *Client) Patch(ctx context.Context, value string, data interface{}) (*http.Response, error) {
+	url, err := c.joinValuesToURL(value)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := c.requestHandler.Request(ctx, data, http.MethodPatch, url, c.clientURL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.httpClient.SendRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.statusOK(response) {
+		return c.statusErrorHandler.StatusError(response)
+	}
+
+	return response, nil
+}
+
+func (c *Client) Delete(ctx context.Context, value string, query *request.Query) (*http.Response, error) {
 	url, err := c.joinValuesToURL(value)
 	if err != nil {
 		return nil, err
 	}
 
-	request, err := c.requestHandler.Request(nil, http.MethodDelete, url, c.clientURL.Host)
+	request, err := c.requestHandler.Request(ctx, nil, http.MethodDelete, url, c.clientURL.Host)
 	if err != nil {
 		return nil, err
 	}
-	c.requestHandler.SetQuery(request, parameterKey, parameterValue)
+	c.requestHandler.SetQuery(request, query)
 
 	response, err := c.httpClient.SendRequest(request)
 	if err != nil {
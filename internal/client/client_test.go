@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,8 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/AdanJSuarez/form3/internal/client/request"
+	"github.com/AdanJSuarez/form3/pkg/retry"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -32,6 +35,9 @@ var (
 	requestPostTest = http.Request{
 		Method: http.MethodPost,
 	}
+	requestPatchTest = http.Request{
+		Method: http.MethodPatch,
+	}
 	requestDeleteTest = http.Request{
 		Method: http.MethodDelete,
 	}
@@ -65,7 +71,7 @@ func TestRunClientSuite(t *testing.T) {
 
 func (ts *TSClient) BeforeTest(_, _ string) {
 	clientURLTest, _ = url.ParseRequestURI(rawBaseURLTest)
-	clientTest = New(*clientURLTest)
+	clientTest = New(*clientURLTest, Config{RetryPolicy: retry.DefaultPolicy()})
 	httpClientMock = newMockHttpClient(ts.T())
 	statusErrorHandlerMock = newMockStatusErrorHandler(ts.T())
 	requestHandlerMock = newMockRequestHandler(ts.T())
@@ -78,8 +84,8 @@ func (ts *TSClient) BeforeTest(_, _ string) {
 func (ts *TSClient) TestGetValidIDReturnsNoError() {
 	httpClientMock.On("SendRequest", mock.Anything).Return(&responseGetTest, nil)
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(&requestGetTest, nil)
-	response, err := clientTest.Get(idTest)
+		mock.Anything, mock.Anything).Return(&requestGetTest, nil)
+	response, err := clientTest.Get(context.Background(), idTest)
 	ts.NoError(err)
 	ts.Equal(&responseGetTest, response)
 }
@@ -87,9 +93,9 @@ func (ts *TSClient) TestGetValidIDReturnsNoError() {
 func (ts *TSClient) TestGetUnknownIDReturnNotFound() {
 	httpClientMock.On("SendRequest", mock.Anything).Return(&responseNotFoundTest, nil)
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(&requestGetTest, nil)
+		mock.Anything, mock.Anything).Return(&requestGetTest, nil)
 	statusErrorHandlerMock.On("StatusError", mock.Anything).Return(nil, fmt.Errorf("not found"))
-	response, err := clientTest.Get(idTest)
+	response, err := clientTest.Get(context.Background(), idTest)
 	ts.ErrorContains(err, "not found")
 	ts.Nil(response)
 }
@@ -97,27 +103,67 @@ func (ts *TSClient) TestGetUnknownIDReturnNotFound() {
 func (ts *TSClient) TestGetWithErrorOnSendRequestReturnsError() {
 	httpClientMock.On("SendRequest", mock.Anything).Return(nil, fmt.Errorf("fakeError1"))
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(&requestGetTest, nil)
+		mock.Anything, mock.Anything).Return(&requestGetTest, nil)
 
-	response, err := clientTest.Get(idTest)
+	response, err := clientTest.Get(context.Background(), idTest)
 	ts.ErrorContains(err, "fakeError1")
 	ts.Nil(response)
 }
 
 func (ts *TSClient) TestGetWithErrorOnRequestReturnsError() {
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(nil, fmt.Errorf("fakeErrorRequest"))
+		mock.Anything, mock.Anything).Return(nil, fmt.Errorf("fakeErrorRequest"))
 
-	response, err := clientTest.Get(idTest)
+	response, err := clientTest.Get(context.Background(), idTest)
 	ts.ErrorContains(err, "fakeErrorRequest")
 	ts.Nil(response)
 }
 
+func (ts *TSClient) TestListValidQueryReturnsNoError() {
+	httpClientMock.On("SendRequest", mock.Anything).Return(&responseGetTest, nil)
+	requestHandlerMock.On("Request", mock.Anything, mock.Anything, http.MethodGet, rawBaseURLTest,
+		mock.Anything).Return(&requestGetTest, nil)
+	query := request.NewQuery().SetPage(0, 100)
+	requestHandlerMock.On("SetQuery", mock.Anything, query).Return().Once()
+	response, err := clientTest.List(context.Background(), query)
+	ts.NoError(err)
+	ts.Equal(&responseGetTest, response)
+}
+
+func (ts *TSClient) TestListWithErrorOnRequestReturnsError() {
+	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(nil, fmt.Errorf("fakeErrorRequestList"))
+	response, err := clientTest.List(context.Background(), request.NewQuery())
+	ts.ErrorContains(err, "fakeErrorRequestList")
+	ts.Nil(response)
+}
+
+func (ts *TSClient) TestListWithErrorOnSendRequestReturnsError() {
+	httpClientMock.On("SendRequest", mock.Anything).Return(nil, fmt.Errorf("fakeErrorList"))
+	requestHandlerMock.On("SetQuery", mock.Anything, mock.Anything).Return()
+	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(&requestGetTest, nil)
+	response, err := clientTest.List(context.Background(), request.NewQuery())
+	ts.ErrorContains(err, "fakeErrorList")
+	ts.Nil(response)
+}
+
+func (ts *TSClient) TestListWithFalseOnStatusOKReturnsError() {
+	httpClientMock.On("SendRequest", mock.Anything).Return(&responseNotFoundTest, nil)
+	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(&requestGetTest, nil)
+	requestHandlerMock.On("SetQuery", mock.Anything, mock.Anything).Return()
+	statusErrorHandlerMock.On("StatusError", mock.Anything).Return(nil, fmt.Errorf("not found"))
+	response, err := clientTest.List(context.Background(), request.NewQuery())
+	ts.ErrorContains(err, "not found")
+	ts.Nil(response)
+}
+
 func (ts *TSClient) TestPostValidDataReturnsNoError() {
 	httpClientMock.On("SendRequest", mock.Anything).Return(&responsePostTest, nil)
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(&requestPostTest, nil)
-	response, err := clientTest.Post(dataTest)
+		mock.Anything, mock.Anything).Return(&requestPostTest, nil)
+	response, err := clientTest.Post(context.Background(), dataTest)
 	ts.NoError(err)
 	ts.Equal(&responsePostTest, response)
 }
@@ -125,16 +171,16 @@ func (ts *TSClient) TestPostValidDataReturnsNoError() {
 func (ts *TSClient) TestPostWithErrorOnSendRequestReturnsError() {
 	httpClientMock.On("SendRequest", mock.Anything).Return(nil, fmt.Errorf("fakeError2"))
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(&requestPostTest, nil)
-	response, err := clientTest.Post(idTest)
+		mock.Anything, mock.Anything).Return(&requestPostTest, nil)
+	response, err := clientTest.Post(context.Background(), idTest)
 	ts.ErrorContains(err, "fakeError2")
 	ts.Nil(response)
 }
 
 func (ts *TSClient) TestPostWithErrorOnRequestReturnsError() {
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(nil, fmt.Errorf("fakeErrorRequest2"))
-	response, err := clientTest.Post(idTest)
+		mock.Anything, mock.Anything).Return(nil, fmt.Errorf("fakeErrorRequest2"))
+	response, err := clientTest.Post(context.Background(), idTest)
 	ts.ErrorContains(err, "fakeErrorRequest2")
 	ts.Nil(response)
 }
@@ -142,27 +188,63 @@ func (ts *TSClient) TestPostWithErrorOnRequestReturnsError() {
 func (ts *TSClient) TestPostWithFalseOnStatusCreatedReturnsError() {
 	httpClientMock.On("SendRequest", mock.Anything).Return(&responseGetTest, nil)
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(&requestPostTest, nil)
+		mock.Anything, mock.Anything).Return(&requestPostTest, nil)
 	statusErrorHandlerMock.On("StatusError", mock.Anything).Return(nil, fmt.Errorf("fakeErrorStatus"))
-	response, err := clientTest.Post(idTest)
+	response, err := clientTest.Post(context.Background(), idTest)
 	ts.ErrorContains(err, "fakeErrorStatus")
 	ts.Nil(response)
 }
 
+func (ts *TSClient) TestPatchValidDataReturnsNoError() {
+	httpClientMock.On("SendRequest", mock.Anything).Return(&responseGetTest, nil)
+	requestHandlerMock.On("Request", mock.Anything, dataTest, http.MethodPatch, rawBaseURLTest+"/"+idTest,
+		mock.Anything).Return(&requestPatchTest, nil)
+	response, err := clientTest.Patch(context.Background(), idTest, dataTest)
+	ts.NoError(err)
+	ts.Equal(&responseGetTest, response)
+}
+
+func (ts *TSClient) TestPatchWithErrorOnRequestReturnsError() {
+	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(nil, fmt.Errorf("fakeErrorRequestPatch"))
+	response, err := clientTest.Patch(context.Background(), idTest, dataTest)
+	ts.ErrorContains(err, "fakeErrorRequestPatch")
+	ts.Nil(response)
+}
+
+func (ts *TSClient) TestPatchWithErrorOnSendRequestReturnsError() {
+	httpClientMock.On("SendRequest", mock.Anything).Return(nil, fmt.Errorf("fakeErrorPatch"))
+	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(&requestPatchTest, nil)
+	response, err := clientTest.Patch(context.Background(), idTest, dataTest)
+	ts.ErrorContains(err, "fakeErrorPatch")
+	ts.Nil(response)
+}
+
+func (ts *TSClient) TestPatchWithFalseOnStatusOKReturnsError() {
+	httpClientMock.On("SendRequest", mock.Anything).Return(&responsePostTest, nil)
+	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything).Return(&requestPatchTest, nil)
+	statusErrorHandlerMock.On("StatusError", mock.Anything).Return(nil, fmt.Errorf("fakeErrorStatusPatch"))
+	response, err := clientTest.Patch(context.Background(), idTest, dataTest)
+	ts.ErrorContains(err, "fakeErrorStatusPatch")
+	ts.Nil(response)
+}
+
 func (ts *TSClient) TestDeleteValidIDAndVersionReturnsNoError() {
 	httpClientMock.On("SendRequest", mock.Anything, mock.Anything, mock.Anything).Return(&responseDeleteTest, nil)
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(&requestDeleteTest, nil)
-	requestHandlerMock.On("SetQuery", mock.Anything, mock.Anything, mock.Anything).Return()
-	response, err := clientTest.Delete(idTest, "version", "0")
+		mock.Anything, mock.Anything).Return(&requestDeleteTest, nil)
+	requestHandlerMock.On("SetQuery", mock.Anything, mock.Anything).Return()
+	response, err := clientTest.Delete(context.Background(), idTest, request.NewQuery().Add("version", "0"))
 	ts.NoError(err)
 	ts.Equal(&responseDeleteTest, response)
 }
 
 func (ts *TSClient) TestDeleteWithErrorOnRequestReturnsError() {
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(nil, fmt.Errorf("fakeErrorRequestDelete"))
-	response, err := clientTest.Delete(idTest, "version", "0")
+		mock.Anything, mock.Anything).Return(nil, fmt.Errorf("fakeErrorRequestDelete"))
+	response, err := clientTest.Delete(context.Background(), idTest, request.NewQuery().Add("version", "0"))
 	ts.ErrorContains(err, "fakeErrorRequestDelete")
 	ts.Nil(response)
 }
@@ -170,10 +252,10 @@ func (ts *TSClient) TestDeleteWithErrorOnRequestReturnsError() {
 func (ts *TSClient) TestDeleteIncorrectIDOrVersionReturnNotFoundError() {
 	httpClientMock.On("SendRequest", mock.Anything, mock.Anything, mock.Anything).Return(&responseNotFoundTest, nil)
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(&requestDeleteTest, nil)
-	requestHandlerMock.On("SetQuery", mock.Anything, mock.Anything, mock.Anything).Return()
+		mock.Anything, mock.Anything).Return(&requestDeleteTest, nil)
+	requestHandlerMock.On("SetQuery", mock.Anything, mock.Anything).Return()
 	statusErrorHandlerMock.On("StatusError", mock.Anything).Return(nil, fmt.Errorf("not found"))
-	response, err := clientTest.Delete(idTest, "version", "0")
+	response, err := clientTest.Delete(context.Background(), idTest, request.NewQuery().Add("version", "0"))
 	ts.ErrorContains(err, "not found")
 	ts.Nil(response)
 }
@@ -181,9 +263,9 @@ func (ts *TSClient) TestDeleteIncorrectIDOrVersionReturnNotFoundError() {
 func (ts *TSClient) TestDeleteWithErrorOnSendRequestReturnsError() {
 	httpClientMock.On("SendRequest", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("fakeErrorDelete"))
 	requestHandlerMock.On("Request", mock.Anything, mock.Anything, mock.Anything,
-		mock.Anything).Return(&requestDeleteTest, nil)
-	requestHandlerMock.On("SetQuery", mock.Anything, mock.Anything, mock.Anything).Return()
-	response, err := clientTest.Delete(idTest, "version", "0")
+		mock.Anything, mock.Anything).Return(&requestDeleteTest, nil)
+	requestHandlerMock.On("SetQuery", mock.Anything, mock.Anything).Return()
+	response, err := clientTest.Delete(context.Background(), idTest, request.NewQuery().Add("version", "0"))
 	ts.ErrorContains(err, "fakeErrorDelete")
 	ts.Nil(response)
 }
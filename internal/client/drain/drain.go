@@ -0,0 +1,25 @@
+package drain
+
+import (
+	"io"
+	"net/http"
+)
+
+// maxDrainBytes limits how much of an unread body is read before closing it.
+// Bigger bodies are closed without reading them, losing the connection.
+const maxDrainBytes = 64 << 10
+
+/*
+Close reads what is left of the response body, up to 64KiB, and closes it. The
+connection of a response is only reused when its body is read to the end and
+closed, so every response not returned to the caller must be closed with it.
+
+It does nothing if the response or its body is nil.
+*/
+func Close(response *http.Response) {
+	if response == nil || response.Body == nil {
+		return
+	}
+	_, _ = io.CopyN(io.Discard, response.Body, maxDrainBytes)
+	response.Body.Close()
+}
@@ -0,0 +1,37 @@
+package drain
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/AdanJSuarez/form3/internal/leaktest"
+	"github.com/stretchr/testify/suite"
+)
+
+type TSDrain struct{ suite.Suite }
+
+func TestRunDrainSuite(t *testing.T) {
+	suite.Run(t, new(TSDrain))
+}
+
+func (ts *TSDrain) TestCloseReadsAndClosesBody() {
+	tracker := leaktest.NewBodyTracker()
+	Close(&http.Response{Body: tracker.Body(`{"error_message": "fake"}`)})
+	tracker.AssertAllClosed(ts.T())
+}
+
+func (ts *TSDrain) TestCloseWithBigBodyClosesWithoutReadingIt() {
+	tracker := leaktest.NewBodyTracker()
+	body := tracker.Body(strings.Repeat("a", 2*maxDrainBytes))
+	Close(&http.Response{Body: body})
+	ts.True(body.Closed())
+	ts.False(body.Drained())
+}
+
+func (ts *TSDrain) TestCloseWithNilResponseOrBodyDoesNothing() {
+	ts.NotPanics(func() {
+		Close(nil)
+		Close(&http.Response{})
+	})
+}
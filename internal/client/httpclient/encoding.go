@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	contentEncodingHeader = "Content-Encoding"
+	contentLengthHeader   = "Content-Length"
+	gzipEncoding          = "gzip"
+	deflateEncoding       = "deflate"
+	decompressErrorFmt    = "failed decompressing %s response body: %v"
+)
+
+/*
+decompress replaces the body of a gzip or deflate encoded response with the
+decompressed one, as the http.Transport does when it sets Accept-Encoding by
+itself. The Content-Encoding and Content-Length headers are removed because they
+do not apply to the new body. Other responses, and the ones without body, like
+the answers to HEAD requests and 204 No Content, are not changed.
+*/
+func (c *HTTPClient) decompress(response *http.Response) error {
+	if !hasBody(response) {
+		return nil
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(response.Header.Get(contentEncodingHeader)))
+	var reader io.ReadCloser
+	var err error
+	switch encoding {
+	case gzipEncoding:
+		reader, err = gzip.NewReader(response.Body)
+	case deflateEncoding:
+		reader, err = newDeflateReader(response.Body)
+	default:
+		return nil
+	}
+	if err != nil {
+		response.Body.Close()
+		return fmt.Errorf(decompressErrorFmt, encoding, err)
+	}
+
+	response.Body = &decompressedBody{reader: reader, body: response.Body}
+	response.Header.Del(contentEncodingHeader)
+	response.Header.Del(contentLengthHeader)
+	response.ContentLength = -1
+	response.Uncompressed = true
+	return nil
+}
+
+// hasBody reports whether the response can have a body to decompress.
+func hasBody(response *http.Response) bool {
+	if response.Body == nil || response.Body == http.NoBody || response.ContentLength == 0 {
+		return false
+	}
+	if response.Request != nil && response.Request.Method == http.MethodHead {
+		return false
+	}
+	return response.StatusCode >= http.StatusOK && response.StatusCode != http.StatusNoContent &&
+		response.StatusCode != http.StatusNotModified
+}
+
+// newDeflateReader reads the zlib format, as the HTTP deflate encoding is defined,
+// or the raw deflate format some servers send instead.
+func newDeflateReader(body io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(body)
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if isZlibHeader(header) {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// isZlibHeader reports whether the two bytes are a zlib header: deflate method
+// and a check value multiple of 31 (RFC 1950).
+func isZlibHeader(header []byte) bool {
+	return header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+// decompressedBody reads the decompressed data and closes both the decompressor
+// and the original body.
+type decompressedBody struct {
+	reader io.ReadCloser
+	body   io.ReadCloser
+}
+
+func (d *decompressedBody) Read(p []byte) (int, error) {
+	return d.reader.Read(p)
+}
+
+func (d *decompressedBody) Close() error {
+	d.reader.Close()
+	return d.body.Close()
+}
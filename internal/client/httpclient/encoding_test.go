@@ -0,0 +1,148 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const encodedDataTest = `{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}}`
+
+type TSEncoding struct{ suite.Suite }
+
+func TestRunEncodingSuite(t *testing.T) {
+	suite.Run(t, new(TSEncoding))
+}
+
+func (ts *TSEncoding) TestDecompressGzipResponse() {
+	response := encodedResponse("gzip", gzipData(encodedDataTest))
+	ts.NoError(httpClientTest.decompress(response))
+	ts.Equal(encodedDataTest, readBody(ts, response))
+	ts.Empty(response.Header.Get("Content-Encoding"))
+	ts.Empty(response.Header.Get("Content-Length"))
+	ts.Equal(int64(-1), response.ContentLength)
+	ts.True(response.Uncompressed)
+}
+
+func (ts *TSEncoding) TestDecompressZlibDeflateResponse() {
+	buffer := &bytes.Buffer{}
+	writer := zlib.NewWriter(buffer)
+	writer.Write([]byte(encodedDataTest))
+	writer.Close()
+
+	response := encodedResponse("deflate", buffer.Bytes())
+	ts.NoError(httpClientTest.decompress(response))
+	ts.Equal(encodedDataTest, readBody(ts, response))
+}
+
+func (ts *TSEncoding) TestDecompressRawDeflateResponse() {
+	buffer := &bytes.Buffer{}
+	writer, _ := flate.NewWriter(buffer, flate.DefaultCompression)
+	writer.Write([]byte(encodedDataTest))
+	writer.Close()
+
+	response := encodedResponse("Deflate", buffer.Bytes())
+	ts.NoError(httpClientTest.decompress(response))
+	ts.Equal(encodedDataTest, readBody(ts, response))
+}
+
+func (ts *TSEncoding) TestDecompressInvalidGzipReturnsError() {
+	response := encodedResponse("gzip", []byte(encodedDataTest))
+	ts.ErrorContains(httpClientTest.decompress(response), "failed decompressing gzip response body")
+}
+
+func (ts *TSEncoding) TestNotEncodedResponseIsNotChanged() {
+	response := encodedResponse("", []byte(encodedDataTest))
+	body := response.Body
+	ts.NoError(httpClientTest.decompress(response))
+	ts.Equal(body, response.Body)
+	ts.False(response.Uncompressed)
+
+	ts.NoError(httpClientTest.decompress(&http.Response{Body: http.NoBody}))
+}
+
+func (ts *TSEncoding) TestResponsesWithoutBodyAreNotDecompressed() {
+	emptyResponse := encodedResponse("gzip", nil)
+	noContentResponse := encodedResponse("deflate", nil)
+	noContentResponse.StatusCode = http.StatusNoContent
+	noContentResponse.ContentLength = -1
+	headResponse := encodedResponse("gzip", nil)
+	headResponse.ContentLength = -1
+	headResponse.Request = &http.Request{Method: http.MethodHead}
+
+	for _, response := range []*http.Response{emptyResponse, noContentResponse, headResponse} {
+		body := response.Body
+		ts.NoError(httpClientTest.decompress(response))
+		ts.Equal(body, response.Body)
+		ts.False(response.Uncompressed)
+	}
+}
+
+func (ts *TSEncoding) TestSendRequestWithEncodedNoContentResponse() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest(http.MethodDelete, server.URL, nil)
+	ts.NoError(err)
+
+	response, err := New(retryPolicyTest, nil, Settings{}).SendRequest(request)
+	ts.NoError(err)
+	ts.Equal(http.StatusNoContent, response.StatusCode)
+	ts.Empty(readBody(ts, response))
+}
+
+func (ts *TSEncoding) TestSendRequestDecompressesServerResponse() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.Contains(r.Header.Get("Accept-Encoding"), "gzip")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(gzipData(encodedDataTest))
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	ts.NoError(err)
+	request.Header.Set("Accept-Encoding", "gzip, deflate")
+
+	response, err := New(retryPolicyTest, nil, Settings{}).SendRequest(request)
+	ts.NoError(err)
+	ts.Equal(encodedDataTest, readBody(ts, response))
+}
+
+func encodedResponse(encoding string, data []byte) *http.Response {
+	header := http.Header{}
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	header.Set("Content-Length", "999")
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        header,
+		ContentLength: int64(len(data)),
+		Body:          io.NopCloser(bytes.NewReader(data)),
+	}
+}
+
+func gzipData(data string) []byte {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	writer.Write([]byte(data))
+	writer.Close()
+	return buffer.Bytes()
+}
+
+func readBody(ts *TSEncoding, response *http.Response) string {
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	ts.NoError(err)
+	return string(data)
+}
@@ -0,0 +1,224 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/AdanJSuarez/form3/internal/client/request"
+	"github.com/AdanJSuarez/form3/pkg/form3test"
+	"github.com/AdanJSuarez/form3/pkg/model"
+	"github.com/AdanJSuarez/form3/pkg/retry"
+	"github.com/stretchr/testify/suite"
+)
+
+const accountIDFaultTest = "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"
+
+var accountFaultTest = model.DataModel{Data: model.Data{
+	ID:             accountIDFaultTest,
+	OrganizationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+	Type:           "accounts",
+	Attributes: model.Attributes{
+		Country: "GB",
+		Name:    []string{"Jane Doe"},
+	},
+}}
+
+// TSFaults sends the requests to the fake API, with faults injected.
+type TSFaults struct {
+	suite.Suite
+	server     *form3test.Server
+	httpClient *HTTPClient
+}
+
+func TestRunFaultsSuite(t *testing.T) {
+	suite.Run(t, new(TSFaults))
+}
+
+func (ts *TSFaults) BeforeTest(_, _ string) {
+	ts.server = form3test.NewServer()
+	ts.httpClient = New(retryPolicyTest, nil, Settings{})
+}
+
+func (ts *TSFaults) AfterTest(_, _ string) {
+	ts.server.Close()
+}
+
+func (ts *TSFaults) createRequest() *http.Request {
+	body, err := json.Marshal(accountFaultTest)
+	ts.Require().NoError(err)
+	postRequest, err := http.NewRequest(http.MethodPost, ts.server.URL+form3test.AccountPath, bytes.NewReader(body))
+	ts.Require().NoError(err)
+	postRequest.Header.Set(request.IDEMPOTENCY_KEY, "fakeIdempotencyKey")
+	return postRequest
+}
+
+func (ts *TSFaults) accountRequest(method, query string) *http.Request {
+	url := ts.server.URL + form3test.AccountPath + "/" + accountIDFaultTest + query
+	request, err := http.NewRequest(method, url, nil)
+	ts.Require().NoError(err)
+	return request
+}
+
+func (ts *TSFaults) createAccount() {
+	response, err := ts.httpClient.SendRequest(ts.createRequest())
+	ts.Require().NoError(err)
+	response.Body.Close()
+	ts.Require().Equal(http.StatusCreated, response.StatusCode)
+}
+
+func (ts *TSFaults) TestTooManyRequestsTwiceWithRetryAfterThenCreated() {
+	ts.httpClient.retryPolicy.BaseDelay = time.Minute
+	ts.httpClient.retryPolicy.MaxDelay = time.Minute
+	ts.server.Inject(http.MethodPost, form3test.AccountPath,
+		form3test.Repeat(2, form3test.RetryAfter(http.StatusTooManyRequests, "1"))...)
+
+	start := time.Now()
+	response, err := ts.httpClient.SendRequest(ts.createRequest())
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+
+	ts.Equal(http.StatusCreated, response.StatusCode)
+	ts.GreaterOrEqual(time.Since(start), 2*time.Second)
+	ts.Less(time.Since(start), time.Minute)
+	ts.Zero(ts.server.PendingFaults())
+	ts.Len(ts.server.Accounts(), 1)
+}
+
+func (ts *TSFaults) TestServiceUnavailableOnEveryAttemptReturnsLastResponse() {
+	ts.server.Inject("", "", form3test.Repeat(retryPolicyTest.MaxAttempts, form3test.Status(http.StatusServiceUnavailable))...)
+
+	response, err := ts.httpClient.SendRequest(ts.accountRequest(http.MethodGet, ""))
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+
+	ts.Equal(http.StatusServiceUnavailable, response.StatusCode)
+	ts.Zero(ts.server.PendingFaults())
+}
+
+func (ts *TSFaults) TestServiceUnavailableOnCreateIsNotRetried() {
+	ts.server.Inject("", "", form3test.Status(http.StatusServiceUnavailable))
+
+	response, err := ts.httpClient.SendRequest(ts.createRequest())
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+
+	ts.Equal(http.StatusServiceUnavailable, response.StatusCode)
+	ts.Zero(ts.server.PendingFaults())
+}
+
+func (ts *TSFaults) TestDelayLongerThanTimeoutReturnsTimeoutError() {
+	ts.httpClient = New(retry.NoRetries(), nil, Settings{Timeout: 50 * time.Millisecond})
+	ts.server.Inject("", "", form3test.Delay(time.Minute))
+
+	response, err := ts.httpClient.SendRequest(ts.accountRequest(http.MethodGet, ""))
+	ts.True(os.IsTimeout(err))
+	ts.Nil(response)
+}
+
+func (ts *TSFaults) TestDelayShorterThanTimeoutIsServed() {
+	ts.createAccount()
+	ts.httpClient = New(retry.NoRetries(), nil, Settings{Timeout: time.Minute})
+	ts.server.Inject("", "", form3test.Delay(50*time.Millisecond))
+
+	response, err := ts.httpClient.SendRequest(ts.accountRequest(http.MethodGet, ""))
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+	ts.Equal(http.StatusOK, response.StatusCode)
+}
+
+func (ts *TSFaults) TestDropConnectionMidBodyFailsReadingBody() {
+	ts.createAccount()
+	ts.server.Inject("", "", form3test.DropConnection())
+
+	response, err := ts.httpClient.SendRequest(ts.accountRequest(http.MethodGet, ""))
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+
+	ts.Equal(http.StatusOK, response.StatusCode)
+	_, err = io.ReadAll(response.Body)
+	ts.ErrorIs(err, io.ErrUnexpectedEOF)
+}
+
+func (ts *TSFaults) TestDropConnectionBeforeAnswerIsRetried() {
+	ts.createAccount()
+	ts.server.Inject(http.MethodDelete, "", form3test.DropConnection())
+
+	response, err := ts.httpClient.SendRequest(ts.accountRequest(http.MethodDelete, "?version=0"))
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+
+	// The first attempt deleted the account before the connection was dropped.
+	ts.Equal(http.StatusNotFound, response.StatusCode)
+	ts.Empty(ts.server.Accounts())
+}
+
+func (ts *TSFaults) TestDropConnectionOnCreateIsNotRetried() {
+	ts.server.Inject(http.MethodPost, "", form3test.DropConnection())
+
+	response, err := ts.httpClient.SendRequest(ts.createRequest())
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+
+	ts.Equal(http.StatusCreated, response.StatusCode)
+	_, err = io.ReadAll(response.Body)
+	ts.ErrorIs(err, io.ErrUnexpectedEOF)
+	ts.Len(ts.server.Accounts(), 1)
+}
+
+func (ts *TSFaults) TestDisconnectOnCreateIsNotRetried() {
+	ts.server.Inject(http.MethodPost, "", form3test.Disconnect())
+
+	// A retry would be answered with 409 Conflict, as the account was created.
+	response, err := ts.httpClient.SendRequest(ts.createRequest())
+	ts.Error(err)
+	ts.Nil(response)
+	ts.Len(ts.server.Accounts(), 1)
+}
+
+func (ts *TSFaults) TestDisconnectOnCreateIsRetriedIfPolicyAllows() {
+	retryPolicy := retryPolicyTest
+	retryPolicy.RetryNonIdempotent = true
+	ts.httpClient = New(retryPolicy, nil, Settings{})
+	ts.server.Inject(http.MethodPost, "", form3test.Disconnect())
+
+	response, err := ts.httpClient.SendRequest(ts.createRequest())
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+
+	// The fake API doesn't deduplicate by Idempotency-Key, as Form3 doesn't.
+	ts.Equal(http.StatusConflict, response.StatusCode)
+	ts.Len(ts.server.Accounts(), 1)
+}
+
+func (ts *TSFaults) TestMalformedJSONIsReturnedAsIs() {
+	ts.server.Inject("", "", form3test.MalformedJSON(http.StatusOK))
+
+	response, err := ts.httpClient.SendRequest(ts.accountRequest(http.MethodGet, ""))
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	ts.NoError(err)
+	ts.False(json.Valid(body))
+}
+
+func (ts *TSFaults) TestGzipResponseIsDecompressed() {
+	ts.createAccount()
+	ts.server.Inject("", "", form3test.Gzip())
+	request := ts.accountRequest(http.MethodGet, "")
+	request.Header.Set("Accept-Encoding", gzipEncoding)
+
+	response, err := ts.httpClient.SendRequest(request)
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+
+	ts.Empty(response.Header.Get(contentEncodingHeader))
+	fetched := model.DataModel{}
+	ts.NoError(json.NewDecoder(response.Body).Decode(&fetched))
+	ts.Equal(accountFaultTest.Data, fetched.Data)
+}
@@ -1,83 +1,252 @@
 package httpclient
 
 import (
+	"context"
 	"fmt"
-	"math"
-	"math/rand"
 	"net/http"
 	"time"
+
+	"github.com/AdanJSuarez/form3/internal/client/drain"
+	"github.com/AdanJSuarez/form3/internal/client/request"
+	"github.com/AdanJSuarez/form3/pkg/retry"
 )
 
 const (
 	defaultTimeout  = 30 * time.Second
-	exponentialBase = 1.5
-	maxJitter       = 10
 	nilRequest      = "nil request"
+	getBodyErrorFmt = "failed getting request body to retry: %v"
 	maxConnections  = 100
-)
-const (
-	maxRetries       float64 = 3
-	periodMultiplier float64 = 1
-)
 
-var timeframe = time.Second
+	attemptErrorLogFmt  = "%s %s attempt %d failed: %v"
+	attemptStatusLogFmt = "%s %s attempt %d: status code %d"
+)
 
 type HTTPClient struct {
-	httpClient httpClient
+	httpClient    httpClient
+	retryPolicy   retry.Policy
+	authenticator request.Authenticator
+	userAgent     string
+	logger        Logger
 }
 
-func New() *HTTPClient {
-	hc := &HTTPClient{}
-	client := &http.Client{
-		Timeout:   defaultTimeout,
-		Transport: hc.basicTransport(),
+// New returns an HTTPClient that retries following the retry policy and, if the
+// authenticator is not nil, authenticates every attempt with it. The settings are
+// expected to be valid (see Settings.Validate).
+func New(retryPolicy retry.Policy, authenticator request.Authenticator, settings Settings) *HTTPClient {
+	client := settings.client()
+	if user, ok := authenticator.(clientUser); ok {
+		user.SetHTTPClient(client)
+	}
+
+	return &HTTPClient{
+		httpClient:    client,
+		retryPolicy:   retryPolicy,
+		authenticator: authenticator,
+		userAgent:     settings.UserAgent,
+		logger:        settings.Logger,
 	}
-	hc.httpClient = client
-	return hc
 }
 
+// SendRequest sends the request, retrying on failure as the retry policy says.
+// The wait between retries is interrupted, returning the context error, as soon
+// as the request context is done. Every retry sends the body again from the
+// start, so a request with a body that cannot be rebuilt (no GetBody) is not retried.
+// Requests of non idempotent methods (POST, PATCH) are only retried when they were
+// not processed (429 Too Many Requests), so a retry cannot repeat a change already
+// done, unless the retry policy says otherwise (see retrySafe).
+// The body of every response not returned is read and closed.
+//
+// If the authenticator can refresh its credentials, a 401 Unauthorized response is
+// retried once, out of the retry policy, after refreshing the credentials.
 func (c *HTTPClient) SendRequest(request *http.Request) (*http.Response, error) {
-	var retries float64 = 0
 	var response *http.Response
 	var err error
+	reauthenticated := false
+
+	if request == nil {
+		return nil, fmt.Errorf(nilRequest)
+	}
 
-	for retries <= maxRetries {
-		if c.hasRetried(retries) {
-			c.exponentialDelay(retries)
+	for attempt := 0; attempt < c.maxAttempts(); attempt++ {
+		if c.hasRetried(attempt) {
+			if !c.replayable(request) || !c.retrySafe(request, response, err) {
+				return response, err
+			}
+			delay, ok := c.retryDelay(response, attempt)
+			if !ok {
+				return response, err
+			}
+			drain.Close(response)
+			if err := c.wait(request.Context(), delay); err != nil {
+				return nil, err
+			}
 		}
 
-		response, err = c.do(request)
+		var sent *http.Request
+		sent, response, err = c.send(request, c.hasRetried(attempt))
+		c.logAttempt(request, attempt, response, err)
+
+		if !reauthenticated && c.reauthenticate(sent, response, err) {
+			reauthenticated = true
+			_, response, err = c.send(request, true)
+			c.logAttempt(request, attempt, response, err)
+		}
 
-		if !c.needRetry(response) {
-			return response, nil
+		if !c.needRetry(request, response, err) {
+			return response, err
 		}
-		retries++
 	}
 	return response, err
 }
 
-func (c *HTTPClient) basicTransport() *http.Transport {
-	transport := http.DefaultTransport.(*http.Transport).Clone()
-	transport.MaxIdleConns = maxConnections
-	transport.MaxIdleConnsPerHost = maxConnections
-	transport.MaxConnsPerHost = maxConnections
+func (c *HTTPClient) maxAttempts() int {
+	if c.retryPolicy.MaxAttempts < 1 {
+		return 1
+	}
+	return c.retryPolicy.MaxAttempts
+}
+
+func (c *HTTPClient) hasRetried(attempt int) bool {
+	return attempt > 0
+}
+
+// needRetry reports whether the attempt failed in a way the retry policy retries.
+// Nothing is retried once the caller gave up: the context of the request is done.
+func (c *HTTPClient) needRetry(request *http.Request, response *http.Response, err error) bool {
+	if request.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return c.retryPolicy.Retryable(err)
+	}
+	return response == nil || c.retryPolicy.RetryableStatusCode(response.StatusCode)
+}
+
+func (c *HTTPClient) replayable(request *http.Request) bool {
+	return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+}
+
+// retrySafe reports whether the request can be sent again after the response or
+// error of the last attempt. A POST or PATCH request may have been processed unless
+// the response is 429 Too Many Requests, so it is only sent again after other
+// failures if the retry policy allows it and the request has an Idempotency-Key
+// header to deduplicate it.
+func (c *HTTPClient) retrySafe(r *http.Request, response *http.Response, err error) bool {
+	switch r.Method {
+	case http.MethodPost, http.MethodPatch:
+		if err == nil && response != nil && response.StatusCode == http.StatusTooManyRequests {
+			return true
+		}
+		return c.retryPolicy.RetryNonIdempotent && r.Header.Get(request.IDEMPOTENCY_KEY) != ""
+	}
+	return true
+}
+
+// send authenticates and sends the request, or a copy of it with a new body if
+// it is a replay of an already sent request. It returns the request sent, nil if
+// it could not be built.
+func (c *HTTPClient) send(request *http.Request, replay bool) (*http.Request, *http.Response, error) {
+	attemptRequest, err := c.attemptRequest(request, replay)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.userAgent != "" {
+		attemptRequest.Header.Set(userAgentHeader, c.userAgent)
+	}
+
+	if err := c.authenticate(attemptRequest); err != nil {
+		return attemptRequest, nil, err
+	}
+
+	response, err := c.do(attemptRequest)
+	return attemptRequest, response, err
+}
+
+// attemptRequest returns the request to send. The first attempt uses the
+// original request, the replays a copy of it with a new body.
+func (c *HTTPClient) attemptRequest(request *http.Request, replay bool) (*http.Request, error) {
+	if !replay || request.GetBody == nil {
+		return request, nil
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf(getBodyErrorFmt, err)
+	}
 
-	return transport
+	attemptRequest := request.Clone(request.Context())
+	attemptRequest.Body = body
+	return attemptRequest, nil
 }
 
-func (c *HTTPClient) hasRetried(retries float64) bool {
-	return retries > 0
+func (c *HTTPClient) authenticate(request *http.Request) error {
+	if c.authenticator == nil {
+		return nil
+	}
+	return c.authenticator.Authenticate(request)
 }
 
-func (c *HTTPClient) needRetry(response *http.Response) bool {
-	return response == nil || response.StatusCode >= http.StatusTooManyRequests
+// reauthenticate reports whether the request must be sent again because the
+// credentials of the attempt sent were rejected and have been refreshed. In that
+// case the body of the response is closed.
+func (c *HTTPClient) reauthenticate(sent *http.Request, response *http.Response, err error) bool {
+	refresher, ok := c.authenticator.(refresher)
+	if !ok || err != nil || response == nil || response.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	if !c.replayable(sent) {
+		return false
+	}
+
+	drain.Close(response)
+	refresher.Refresh(sent)
+	return true
 }
 
-func (c *HTTPClient) exponentialDelay(retries float64) {
-	rand.Seed(time.Now().UnixNano())
-	period := time.Duration(math.Pow(exponentialBase, retries)*periodMultiplier) * timeframe
-	jitter := time.Duration(rand.Intn(maxJitter)) * timeframe
-	time.Sleep(period + jitter)
+// retryDelay returns the delay advised by the response headers, if any, or the
+// one of the retry policy otherwise. It returns false if the advised delay is
+// longer than the retry policy allows to wait.
+func (c *HTTPClient) retryDelay(response *http.Response, attempt int) (time.Duration, bool) {
+	if response == nil {
+		return c.retryPolicy.Delay(attempt), true
+	}
+
+	advisedDelay, ok := retry.AdvisedDelay(response.Header, time.Now())
+	if !ok {
+		return c.retryPolicy.Delay(attempt), true
+	}
+
+	maxAdvisedDelay := c.retryPolicy.MaxAdvisedDelay
+	return advisedDelay, maxAdvisedDelay == 0 || advisedDelay <= maxAdvisedDelay
+}
+
+// logAttempt logs the outcome of an attempt. The query of the URL is left out, as
+// it is of no use to follow the attempts.
+func (c *HTTPClient) logAttempt(request *http.Request, attempt int, response *http.Response, err error) {
+	if c.logger == nil {
+		return
+	}
+
+	target := request.URL.Scheme + "://" + request.URL.Host + request.URL.Path
+	switch {
+	case err != nil:
+		c.logger.Printf(attemptErrorLogFmt, request.Method, target, attempt+1, err)
+	case response != nil:
+		c.logger.Printf(attemptStatusLogFmt, request.Method, target, attempt+1, response.StatusCode)
+	}
+}
+
+func (c *HTTPClient) wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 func (c *HTTPClient) do(request *http.Request) (*http.Response, error) {
@@ -88,5 +257,8 @@ func (c *HTTPClient) do(request *http.Request) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
-	return response, err
+	if err := c.decompress(response); err != nil {
+		return nil, err
+	}
+	return response, nil
 }
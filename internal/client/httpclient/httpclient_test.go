@@ -2,14 +2,22 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/AdanJSuarez/form3/internal/client/request"
+	"github.com/AdanJSuarez/form3/internal/leaktest"
+	"github.com/AdanJSuarez/form3/pkg/retry"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -19,6 +27,15 @@ const (
 )
 
 var (
+	retryPolicyTest = retry.Policy{
+		MaxAttempts:          4,
+		BaseDelay:            time.Millisecond,
+		Multiplier:           1.5,
+		MaxDelay:             10 * time.Millisecond,
+		Jitter:               retry.FullJitter,
+		RetryableStatusCodes: retry.DefaultPolicy().RetryableStatusCodes,
+		RetryableError:       retry.NetworkError,
+	}
 	httpClientTest      *HTTPClient
 	mockHTTPClient      *mockHttpClient
 	dataBytesMarshal, _ = json.Marshal(dataTest)
@@ -60,6 +77,8 @@ var (
 	}
 )
 
+// timeoutError is like the error of an attempt exceeding the http.Client Timeout,
+// which wraps context.DeadlineExceeded.
 type timeoutError struct {
 	error
 }
@@ -73,6 +92,9 @@ func (e timeoutError) Temporary() bool {
 func (e timeoutError) Error() string {
 	return "timeout error super fake"
 }
+func (e timeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
 
 type TSHTTPClient struct{ suite.Suite }
 
@@ -81,8 +103,7 @@ func TestRunSuite(t *testing.T) {
 }
 
 func (ts *TSHTTPClient) BeforeTest(_, _ string) {
-	timeframe = time.Millisecond
-	httpClientTest = New()
+	httpClientTest = New(retryPolicyTest, nil, Settings{})
 	ts.IsType(new(HTTPClient), httpClientTest)
 	mockHTTPClient = newMockHttpClient(ts.T())
 	httpClientTest.httpClient = mockHTTPClient
@@ -164,3 +185,555 @@ func (ts *TSHTTPClient) TestSendRequestReturnsGatewayTimeoutErrorAfterRetry() {
 	ts.NoError(err)
 	ts.Equal(&responseGatewayTimeoutErrorTest, response)
 }
+
+func (ts *TSHTTPClient) TestSendRequestRetriesUpToMaxAttempts() {
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseServiceUnavailableErrorTest, nil).Times(4)
+	response, err := httpClientTest.SendRequest(requestTest)
+	ts.NoError(err)
+	ts.Equal(&responseServiceUnavailableErrorTest, response)
+	mockHTTPClient.AssertNumberOfCalls(ts.T(), "Do", 4)
+}
+
+func (ts *TSHTTPClient) TestSendRequestWithNoRetriesPolicySendsOnce() {
+	httpClientTest.retryPolicy = retry.NoRetries()
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseServiceUnavailableErrorTest, nil).Once()
+	response, err := httpClientTest.SendRequest(requestTest)
+	ts.NoError(err)
+	ts.Equal(&responseServiceUnavailableErrorTest, response)
+}
+
+func (ts *TSHTTPClient) TestSendRequestNotRetryableStatusSendsOnce() {
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseNotFoundTest, nil).Once()
+	response, err := httpClientTest.SendRequest(requestTest)
+	ts.NoError(err)
+	ts.Equal(&responseNotFoundTest, response)
+}
+
+func (ts *TSHTTPClient) TestSendRequestNotRetryableErrorSendsOnce() {
+	httpClientTest.retryPolicy.RetryableError = nil
+	mockHTTPClient.On("Do", mock.Anything).Return(nil, fmt.Errorf("fakeError")).Once()
+	response, err := httpClientTest.SendRequest(requestTest)
+	ts.ErrorContains(err, "fakeError")
+	ts.Nil(response)
+}
+
+func (ts *TSHTTPClient) TestSendRequestWithContextDoneStopsWaitingToRetry() {
+	httpClientTest.retryPolicy.BaseDelay = time.Minute
+	httpClientTest.retryPolicy.MaxDelay = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	request := requestTest.WithContext(ctx)
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseServiceUnavailableErrorTest, nil).Once()
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	response, err := httpClientTest.SendRequest(request)
+	ts.ErrorIs(err, context.Canceled)
+	ts.Nil(response)
+	ts.Less(time.Since(start), time.Second)
+}
+
+func (ts *TSHTTPClient) TestWaitWithContextDoneReturnsError() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ts.ErrorIs(httpClientTest.wait(ctx, time.Minute), context.Canceled)
+}
+
+func (ts *TSHTTPClient) TestWaitReturnsNoError() {
+	ts.NoError(httpClientTest.wait(context.Background(), time.Millisecond))
+}
+
+func (ts *TSHTTPClient) TestSendRequestRetriesWithIdenticalBodyAndHeaders() {
+	httpClientTest.retryPolicy.RetryNonIdempotent = true
+	request := postRequestTest(ts, "http://fake.form3.tech/v1/organisation/accounts")
+	bodies := [][]byte{}
+	headers := []http.Header{}
+	mockHTTPClient.On("Do", mock.Anything).Run(func(args mock.Arguments) {
+		attemptRequest := args.Get(0).(*http.Request)
+		body, err := io.ReadAll(attemptRequest.Body)
+		ts.NoError(err)
+		bodies = append(bodies, body)
+		headers = append(headers, attemptRequest.Header)
+	}).Return(&responseServiceUnavailableErrorTest, nil).Times(4)
+
+	_, err := httpClientTest.SendRequest(request)
+	ts.NoError(err)
+	ts.Len(bodies, 4)
+	for i := range bodies {
+		ts.Equal([]byte(dataTest), bodies[i])
+		ts.Equal(request.Header, headers[i])
+	}
+}
+
+func (ts *TSHTTPClient) TestSendRequestAgainstServerRetriesWithIdenticalPayload() {
+	var mu sync.Mutex
+	bodies := []string{}
+	headers := []http.Header{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, string(body))
+		headers = append(headers, r.Header.Clone())
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	retryPolicy := retryPolicyTest
+	retryPolicy.RetryNonIdempotent = true
+	httpClient := New(retryPolicy, nil, Settings{})
+
+	response, err := httpClient.SendRequest(postRequestTest(ts, server.URL))
+	ts.NoError(err)
+	ts.Equal(http.StatusCreated, response.StatusCode)
+	ts.Len(bodies, 3)
+	for i := range bodies {
+		ts.Equal(dataTest, bodies[i])
+		ts.Equal(fmt.Sprint(len(dataTest)), headers[i].Get("Content-Length"))
+		ts.Equal(headers[0].Get("Digest"), headers[i].Get("Digest"))
+	}
+}
+
+func (ts *TSHTTPClient) TestSendRequestAgainstServerRetriesClientTimeout() {
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			<-release
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+	httpClient := New(retryPolicyTest, nil, Settings{Timeout: 50 * time.Millisecond})
+	request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	ts.Require().NoError(err)
+
+	response, err := httpClient.SendRequest(request)
+	ts.Require().NoError(err)
+	response.Body.Close()
+	ts.Equal(http.StatusOK, response.StatusCode)
+	ts.Equal(int32(2), attempts.Load())
+}
+
+func (ts *TSHTTPClient) TestSendRequestAgainstServerWithContextDeadlineIsNotRetried() {
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	httpClient := New(retryPolicyTest, nil, Settings{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	ts.Require().NoError(err)
+
+	_, err = httpClient.SendRequest(request)
+	ts.ErrorIs(err, context.DeadlineExceeded)
+	ts.Equal(int32(1), attempts.Load())
+}
+
+func (ts *TSHTTPClient) TestSendRequestWithBodyNotReplayableIsNotRetried() {
+	request := postRequestTest(ts, "http://fake.form3.tech/v1/organisation/accounts")
+	request.Body = io.NopCloser(bytes.NewBufferString(dataTest))
+	request.GetBody = nil
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseServiceUnavailableErrorTest, nil).Once()
+
+	response, err := httpClientTest.SendRequest(request)
+	ts.NoError(err)
+	ts.Equal(&responseServiceUnavailableErrorTest, response)
+}
+
+func (ts *TSHTTPClient) TestSendRequestWithErrorOnGetBodyReturnsError() {
+	httpClientTest.retryPolicy.RetryNonIdempotent = true
+	request := postRequestTest(ts, "http://fake.form3.tech/v1/organisation/accounts")
+	request.GetBody = func() (io.ReadCloser, error) {
+		return nil, fmt.Errorf("fakeGetBodyError")
+	}
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseServiceUnavailableErrorTest, nil).Once()
+
+	response, err := httpClientTest.SendRequest(request)
+	ts.ErrorContains(err, "fakeGetBodyError")
+	ts.Nil(response)
+}
+
+func postRequestTest(ts *TSHTTPClient, url string) *http.Request {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(dataTest)))
+	ts.NoError(err)
+	request.Header.Add("Digest", "sha-256=fakeDigest")
+	request.Header.Add("Idempotency-Key", "fakeIdempotencyKey")
+	return request
+}
+
+func (ts *TSHTTPClient) TestSendRequestWaitsRetryAfterInsteadOfPolicyDelay() {
+	httpClientTest.retryPolicy.BaseDelay = time.Minute
+	httpClientTest.retryPolicy.MaxDelay = time.Minute
+	responseRetryAfter := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"0"}},
+		Body:       io.NopCloser(bytes.NewBuffer([]byte(""))),
+	}
+	mockHTTPClient.On("Do", mock.Anything).Return(responseRetryAfter, nil).Once()
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseGetTest, nil).Once()
+
+	start := time.Now()
+	response, err := httpClientTest.SendRequest(requestTest)
+	ts.NoError(err)
+	ts.Equal(&responseGetTest, response)
+	ts.Less(time.Since(start), time.Second)
+}
+
+func (ts *TSHTTPClient) TestSendRequestWithAdvisedDelayTooLongIsNotRetried() {
+	responseRetryAfter := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{"3600"}},
+		Body:       io.NopCloser(bytes.NewBuffer([]byte(""))),
+	}
+	httpClientTest.retryPolicy.MaxAdvisedDelay = time.Minute
+	mockHTTPClient.On("Do", mock.Anything).Return(responseRetryAfter, nil).Once()
+
+	response, err := httpClientTest.SendRequest(requestTest)
+	ts.NoError(err)
+	ts.Equal(responseRetryAfter, response)
+}
+
+func (ts *TSHTTPClient) TestRetryDelayUsesRateLimitHeaders() {
+	response := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header: http.Header{
+			"X-Ratelimit-Remaining": []string{"0"},
+			"X-Ratelimit-Reset":     []string{"2"},
+		},
+	}
+	delay, ok := httpClientTest.retryDelay(response, 1)
+	ts.True(ok)
+	ts.Equal(2*time.Second, delay)
+}
+
+func (ts *TSHTTPClient) TestRetryDelayWithoutAdviceUsesPolicy() {
+	delay, ok := httpClientTest.retryDelay(&responseServiceUnavailableErrorTest, 1)
+	ts.True(ok)
+	ts.LessOrEqual(delay, retryPolicyTest.MaxDelay)
+
+	delay, ok = httpClientTest.retryDelay(nil, 1)
+	ts.True(ok)
+	ts.LessOrEqual(delay, retryPolicyTest.MaxDelay)
+}
+
+func (ts *TSHTTPClient) TestSendPostRequestWithoutIdempotencyKeyIsNotRetried() {
+	request := postRequestTest(ts, "http://fake.form3.tech/v1/organisation/accounts")
+	request.Header.Del("Idempotency-Key")
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseInternalServerErrorTest, nil).Once()
+
+	response, err := httpClientTest.SendRequest(request)
+	ts.NoError(err)
+	ts.Equal(&responseInternalServerErrorTest, response)
+	mockHTTPClient.AssertNumberOfCalls(ts.T(), "Do", 1)
+}
+
+func (ts *TSHTTPClient) TestSendPostRequestWithServerErrorIsNotRetried() {
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseInternalServerErrorTest, nil).Once()
+
+	response, err := httpClientTest.SendRequest(postRequestTest(ts, "http://fake.form3.tech/v1/organisation/accounts"))
+	ts.NoError(err)
+	ts.Equal(&responseInternalServerErrorTest, response)
+	mockHTTPClient.AssertNumberOfCalls(ts.T(), "Do", 1)
+}
+
+func (ts *TSHTTPClient) TestSendPatchRequestWithNetworkErrorIsNotRetried() {
+	request := postRequestTest(ts, "http://fake.form3.tech/v1/organisation/accounts/fakeID")
+	request.Method = http.MethodPatch
+	mockHTTPClient.On("Do", mock.Anything).Return(nil, timeoutError{}).Once()
+
+	response, err := httpClientTest.SendRequest(request)
+	ts.True(os.IsTimeout(err))
+	ts.Nil(response)
+	mockHTTPClient.AssertNumberOfCalls(ts.T(), "Do", 1)
+}
+
+func (ts *TSHTTPClient) TestSendPostRequestWithTooManyRequestsIsRetried() {
+	request := postRequestTest(ts, "http://fake.form3.tech/v1/organisation/accounts")
+	request.Header.Del("Idempotency-Key")
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseTooManyRequestTest, nil).Once()
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseGetTest, nil).Once()
+
+	response, err := httpClientTest.SendRequest(request)
+	ts.NoError(err)
+	ts.Equal(&responseGetTest, response)
+}
+
+func (ts *TSHTTPClient) TestSendPostRequestReusesIdempotencyKeyOnRetries() {
+	keys := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	retryPolicy := retryPolicyTest
+	retryPolicy.RetryNonIdempotent = true
+	httpClient := New(retryPolicy, nil, Settings{})
+
+	response, err := httpClient.SendRequest(postRequestTest(ts, server.URL))
+	ts.NoError(err)
+	defer response.Body.Close()
+	ts.Equal(http.StatusCreated, response.StatusCode)
+	ts.Equal([]string{"fakeIdempotencyKey", "fakeIdempotencyKey", "fakeIdempotencyKey"}, keys)
+}
+
+func (ts *TSHTTPClient) TestSendRequestClosesBodiesOfRetriedResponses() {
+	tracker := leaktest.NewBodyTracker()
+	for i := 0; i < retryPolicyTest.MaxAttempts-1; i++ {
+		mockHTTPClient.On("Do", mock.Anything).Return(&http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       tracker.Body(`{"error_message": "fake unavailable"}`),
+		}, nil).Once()
+	}
+	last := &http.Response{StatusCode: http.StatusServiceUnavailable, Body: tracker.Body("last")}
+	mockHTTPClient.On("Do", mock.Anything).Return(last, nil).Once()
+
+	response, err := httpClientTest.SendRequest(requestTest)
+	ts.NoError(err)
+	ts.Equal(last, response)
+	ts.Equal(1, tracker.Open())
+
+	response.Body.Close()
+	ts.Equal(0, tracker.Open())
+}
+
+func (ts *TSHTTPClient) TestSendRequestWithContextDoneClosesBodyOfRetriedResponse() {
+	tracker := leaktest.NewBodyTracker()
+	httpClientTest.retryPolicy.BaseDelay = time.Minute
+	httpClientTest.retryPolicy.MaxDelay = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	mockHTTPClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusBadGateway,
+		Body:       tracker.Body("fake bad gateway"),
+	}, nil).Once()
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	response, err := httpClientTest.SendRequest(requestTest.WithContext(ctx))
+	ts.ErrorIs(err, context.Canceled)
+	ts.Nil(response)
+	tracker.AssertAllClosed(ts.T())
+}
+
+func (ts *TSHTTPClient) TestSendRequestAgainstServerReusesConnectionsOnRetries() {
+	checkGoroutines := leaktest.CheckGoroutines(ts.T())
+	newConnections := 0
+	var mu sync.Mutex
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error_message": "fake internal error"}`)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		if state == http.StateNew {
+			newConnections++
+		}
+	}
+	server.Start()
+	httpClient := New(retryPolicyTest, nil, Settings{})
+
+	for i := 0; i < 10; i++ {
+		request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		ts.NoError(err)
+		response, err := httpClient.SendRequest(request)
+		ts.NoError(err)
+		ts.Equal(http.StatusInternalServerError, response.StatusCode)
+		_, _ = io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+	}
+
+	mu.Lock()
+	ts.Equal(1, newConnections)
+	mu.Unlock()
+	server.Close()
+	checkGoroutines()
+}
+
+type authenticatorTest struct {
+	calls int
+	err   error
+}
+
+func (a *authenticatorTest) Authenticate(request *http.Request) error {
+	a.calls++
+	request.Header.Set("Authorization", fmt.Sprintf("fake %d", a.calls))
+	return a.err
+}
+
+func (ts *TSHTTPClient) TestSendRequestAuthenticatesEveryAttempt() {
+	authenticator := &authenticatorTest{}
+	httpClientTest.authenticator = authenticator
+	mockHTTPClient.On("Do", mock.MatchedBy(func(request *http.Request) bool {
+		return request.Header.Get("Authorization") == "fake 1"
+	})).Return(&responseBadGatewayErrorTest, nil).Once()
+	mockHTTPClient.On("Do", mock.MatchedBy(func(request *http.Request) bool {
+		return request.Header.Get("Authorization") == "fake 2"
+	})).Return(&responseGetTest, nil).Once()
+
+	response, err := httpClientTest.SendRequest(&http.Request{Header: http.Header{}})
+	ts.NoError(err)
+	ts.Equal(&responseGetTest, response)
+	ts.Equal(2, authenticator.calls)
+}
+
+func (ts *TSHTTPClient) TestSendRequestWithAuthenticationErrorIsNotSent() {
+	httpClientTest.authenticator = &authenticatorTest{err: fmt.Errorf("fake signing error")}
+
+	response, err := httpClientTest.SendRequest(&http.Request{Header: http.Header{}})
+	ts.ErrorContains(err, "fake signing error")
+	ts.Nil(response)
+	mockHTTPClient.AssertNotCalled(ts.T(), "Do", mock.Anything)
+}
+
+type refresherTest struct {
+	authenticatorTest
+	refreshed int
+	rejected  []string
+}
+
+func (r *refresherTest) Refresh(rejected *http.Request) {
+	r.refreshed++
+	r.rejected = append(r.rejected, rejected.Header.Get("Authorization"))
+}
+
+func (ts *TSHTTPClient) TestSendRequestUnauthorizedIsSentOnceMoreAfterRefresh() {
+	authenticator := &refresherTest{}
+	httpClientTest.authenticator = authenticator
+	tracker := leaktest.NewBodyTracker()
+	mockHTTPClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusUnauthorized,
+		Body:       tracker.Body("fake unauthorized"),
+	}, nil).Once()
+	mockHTTPClient.On("Do", mock.Anything).Return(&responseGetTest, nil).Once()
+
+	response, err := httpClientTest.SendRequest(&http.Request{Header: http.Header{}})
+	ts.NoError(err)
+	ts.Equal(&responseGetTest, response)
+	ts.Equal(1, authenticator.refreshed)
+	ts.Equal(2, authenticator.calls)
+	tracker.AssertAllClosed(ts.T())
+}
+
+func (ts *TSHTTPClient) TestSendRequestUnauthorizedOnRetryRefreshesCredentialsSent() {
+	authenticator := &refresherTest{}
+	httpClientTest.authenticator = authenticator
+	authorizations := []string{}
+	mockHTTPClient.On("Do", mock.Anything).Run(func(args mock.Arguments) {
+		authorizations = append(authorizations, args.Get(0).(*http.Request).Header.Get("Authorization"))
+	}).Return(&http.Response{StatusCode: http.StatusTooManyRequests, Body: http.NoBody}, nil).Once()
+	mockHTTPClient.On("Do", mock.Anything).Run(func(args mock.Arguments) {
+		authorizations = append(authorizations, args.Get(0).(*http.Request).Header.Get("Authorization"))
+	}).Return(&http.Response{StatusCode: http.StatusUnauthorized, Body: http.NoBody}, nil).Once()
+	mockHTTPClient.On("Do", mock.Anything).Run(func(args mock.Arguments) {
+		authorizations = append(authorizations, args.Get(0).(*http.Request).Header.Get("Authorization"))
+	}).Return(&responseGetTest, nil).Once()
+
+	response, err := httpClientTest.SendRequest(postRequestTest(ts, "http://fake.form3.tech/v1/organisation/accounts"))
+	ts.NoError(err)
+	ts.Equal(&responseGetTest, response)
+	ts.Equal([]string{"fake 1", "fake 2", "fake 3"}, authorizations)
+	ts.Equal([]string{"fake 2"}, authenticator.rejected)
+}
+
+func (ts *TSHTTPClient) TestSendRequestUnauthorizedTwiceReturnsResponse() {
+	authenticator := &refresherTest{}
+	httpClientTest.authenticator = authenticator
+	responseUnauthorized := &http.Response{StatusCode: http.StatusUnauthorized, Body: http.NoBody}
+	mockHTTPClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusUnauthorized, Body: http.NoBody}, nil).Once()
+	mockHTTPClient.On("Do", mock.Anything).Return(responseUnauthorized, nil).Once()
+
+	response, err := httpClientTest.SendRequest(&http.Request{Header: http.Header{}})
+	ts.NoError(err)
+	ts.Equal(responseUnauthorized, response)
+	ts.Equal(1, authenticator.refreshed)
+}
+
+func (ts *TSHTTPClient) TestSendRequestUnauthorizedWithoutRefresherIsNotSentAgain() {
+	httpClientTest.authenticator = &authenticatorTest{}
+	responseUnauthorized := &http.Response{StatusCode: http.StatusUnauthorized, Body: http.NoBody}
+	mockHTTPClient.On("Do", mock.Anything).Return(responseUnauthorized, nil).Once()
+
+	response, err := httpClientTest.SendRequest(&http.Request{Header: http.Header{}})
+	ts.NoError(err)
+	ts.Equal(responseUnauthorized, response)
+}
+
+func (ts *TSHTTPClient) TestSendRequestAgainstServerGetsNewTokenOnUnauthorized() {
+	var mu sync.Mutex
+	tokens := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		tokens++
+		fmt.Fprintf(w, `{"access_token": "token%d", "expires_in": 3600}`, tokens)
+	}))
+	defer tokenServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer apiServer.Close()
+
+	authenticator, err := request.NewTokenAuthenticator(tokenServer.URL, "fakeClientID", "fakeSecret")
+	ts.NoError(err)
+	httpClient := New(retry.NoRetries(), authenticator, Settings{})
+
+	response, err := httpClient.SendRequest(postRequestTest(ts, apiServer.URL))
+	ts.NoError(err)
+	defer response.Body.Close()
+	ts.Equal(http.StatusCreated, response.StatusCode)
+	ts.Equal(2, tokens)
+}
+
+func (ts *TSHTTPClient) TestTokensAreRequestedWithTheConfiguredTransport() {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token": "token1", "expires_in": 3600}`)
+	}))
+	defer tokenServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer apiServer.Close()
+
+	var mu sync.Mutex
+	hosts := []string{}
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		mu.Lock()
+		hosts = append(hosts, r.URL.Host)
+		mu.Unlock()
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	authenticator, err := request.NewTokenAuthenticator(tokenServer.URL, "fakeClientID", "fakeSecret")
+	ts.NoError(err)
+	httpClient := New(retry.NoRetries(), authenticator, Settings{Transport: transport})
+
+	response, err := httpClient.SendRequest(postRequestTest(ts, apiServer.URL))
+	ts.NoError(err)
+	defer response.Body.Close()
+	ts.Equal(http.StatusCreated, response.StatusCode)
+	ts.Equal([]string{tokenServer.Listener.Addr().String(), apiServer.Listener.Addr().String()}, hosts)
+}
+
+// roundTripperFunc is an http.RoundTripper that sends the requests with the function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
@@ -8,3 +8,15 @@ import (
 type httpClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
+
+// refresher is an authenticator whose credentials can be refreshed when the API
+// rejects them.
+type refresher interface {
+	Refresh(rejected *http.Request)
+}
+
+// clientUser is an authenticator that sends requests of its own, like to get a
+// token. They are sent with the http client of the API requests.
+type clientUser interface {
+	SetHTTPClient(client *http.Client)
+}
@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	userAgentHeader          = "User-Agent"
+	negativeTimeoutError     = "timeout cannot be negative, got %v"
+	negativeConnectionsError = "max connections cannot be negative, got %d"
+	clientConflictError      = "an http client cannot be combined with a transport, a timeout or max connections"
+	transportConflictError   = "a transport cannot be combined with max connections"
+)
+
+// Logger logs the attempts of the requests. The standard *log.Logger is a Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Settings holds the settings of the underlying http client. The zero value uses
+// the defaults.
+type Settings struct {
+	// HTTPClient sends the requests. Nil means a client built from the rest of
+	// the settings.
+	HTTPClient *http.Client
+	// Transport sends the requests of the built client. Nil means a clone of
+	// http.DefaultTransport limited to MaxConnections.
+	Transport http.RoundTripper
+	// Timeout limits every attempt of the built client. Zero means 30 seconds.
+	Timeout time.Duration
+	// MaxConnections limits the connections of the default transport. Zero
+	// means 100.
+	MaxConnections int
+	// UserAgent is the User-Agent header of every request. Empty means the Go
+	// default.
+	UserAgent string
+	// Logger logs every attempt. Nil means nothing is logged.
+	Logger Logger
+}
+
+// Validate returns an error if a setting is out of range or the settings
+// conflict, since a given HTTPClient ignores the settings used to build one.
+func (s Settings) Validate() error {
+	if s.Timeout < 0 {
+		return fmt.Errorf(negativeTimeoutError, s.Timeout)
+	}
+	if s.MaxConnections < 0 {
+		return fmt.Errorf(negativeConnectionsError, s.MaxConnections)
+	}
+	if s.HTTPClient != nil && (s.Transport != nil || s.Timeout != 0 || s.MaxConnections != 0) {
+		return fmt.Errorf(clientConflictError)
+	}
+	if s.Transport != nil && s.MaxConnections != 0 {
+		return fmt.Errorf(transportConflictError)
+	}
+	return nil
+}
+
+func (s Settings) client() *http.Client {
+	if s.HTTPClient != nil {
+		return s.HTTPClient
+	}
+
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	transport := s.Transport
+	if transport == nil {
+		transport = s.basicTransport()
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+func (s Settings) basicTransport() *http.Transport {
+	connections := s.MaxConnections
+	if connections == 0 {
+		connections = maxConnections
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = connections
+	transport.MaxIdleConnsPerHost = connections
+	transport.MaxConnsPerHost = connections
+
+	return transport
+}
@@ -0,0 +1,114 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdanJSuarez/form3/pkg/retry"
+	"github.com/stretchr/testify/suite"
+)
+
+type loggerTest struct {
+	lines []string
+}
+
+func (l *loggerTest) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+type TSSettings struct{ suite.Suite }
+
+func TestRunTSSettings(t *testing.T) {
+	suite.Run(t, new(TSSettings))
+}
+
+func (ts *TSSettings) TestZeroSettingsAreValid() {
+	ts.NoError(Settings{}.Validate())
+}
+
+func (ts *TSSettings) TestNegativeValuesAreNotValid() {
+	ts.ErrorContains(Settings{Timeout: -time.Second}.Validate(), "timeout cannot be negative")
+	ts.ErrorContains(Settings{MaxConnections: -1}.Validate(), "max connections cannot be negative")
+}
+
+func (ts *TSSettings) TestHTTPClientConflictsWithClientSettings() {
+	client := &http.Client{}
+	ts.Error(Settings{HTTPClient: client, Transport: http.DefaultTransport}.Validate())
+	ts.Error(Settings{HTTPClient: client, Timeout: time.Second}.Validate())
+	ts.Error(Settings{HTTPClient: client, MaxConnections: 1}.Validate())
+	ts.NoError(Settings{HTTPClient: client, UserAgent: "agent", Logger: &loggerTest{}}.Validate())
+}
+
+func (ts *TSSettings) TestTransportConflictsWithMaxConnections() {
+	ts.ErrorContains(Settings{Transport: http.DefaultTransport, MaxConnections: 1}.Validate(),
+		"max connections")
+	ts.NoError(Settings{Transport: http.DefaultTransport, Timeout: time.Second}.Validate())
+}
+
+func (ts *TSSettings) TestDefaultClient() {
+	client := Settings{}.client()
+	ts.Equal(defaultTimeout, client.Timeout)
+	transport, ok := client.Transport.(*http.Transport)
+	ts.True(ok)
+	ts.Equal(maxConnections, transport.MaxConnsPerHost)
+}
+
+func (ts *TSSettings) TestClientBuiltFromSettings() {
+	client := Settings{Timeout: time.Second, MaxConnections: 5}.client()
+	ts.Equal(time.Second, client.Timeout)
+	transport, ok := client.Transport.(*http.Transport)
+	ts.True(ok)
+	ts.Equal(5, transport.MaxConnsPerHost)
+	ts.Equal(5, transport.MaxIdleConnsPerHost)
+
+	client = Settings{Transport: http.DefaultTransport}.client()
+	ts.Equal(http.DefaultTransport, client.Transport)
+}
+
+func (ts *TSSettings) TestGivenHTTPClientIsUsed() {
+	client := &http.Client{}
+	ts.Same(client, Settings{HTTPClient: client}.client())
+}
+
+func (ts *TSSettings) TestUserAgentIsSetAndAttemptsAreLogged() {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get(userAgentHeader))
+		if len(userAgents) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	logger := &loggerTest{}
+	httpClient := New(retryPolicyTest, nil, Settings{UserAgent: "agent/1.0", Logger: logger})
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/accounts?secret=value", nil)
+	ts.NoError(err)
+
+	response, err := httpClient.SendRequest(request)
+	ts.NoError(err)
+	defer response.Body.Close()
+	ts.Equal([]string{"agent/1.0", "agent/1.0"}, userAgents)
+	ts.Equal([]string{
+		"GET " + server.URL + "/accounts attempt 1: status code 503",
+		"GET " + server.URL + "/accounts attempt 2: status code 200",
+	}, logger.lines)
+}
+
+func (ts *TSSettings) TestFailedAttemptsAreLogged() {
+	logger := &loggerTest{}
+	httpClient := New(retry.NoRetries(), nil, Settings{Logger: logger})
+	request, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:0/accounts", nil)
+	ts.NoError(err)
+
+	_, err = httpClient.SendRequest(request)
+	ts.Error(err)
+	ts.Len(logger.lines, 1)
+	ts.True(strings.HasPrefix(logger.lines[0], "GET http://127.0.0.1:0/accounts attempt 1 failed:"))
+}
@@ -1,7 +1,10 @@
 package client
 
 import (
+	"context"
 	"net/http"
+
+	"github.com/AdanJSuarez/form3/internal/client/request"
 )
 
 //go:generate mockery --inpackage --name=httpClient
@@ -13,8 +16,8 @@ type httpClient interface {
 }
 
 type requestHandler interface {
-	Request(data interface{}, method, url, host string) (*http.Request, error)
-	SetQuery(request *http.Request, parameterKey, parameterValue string)
+	Request(ctx context.Context, data interface{}, method, url, host string) (*http.Request, error)
+	SetQuery(request *http.Request, query *request.Query)
 }
 
 type statusErrorHandler interface {
@@ -0,0 +1,79 @@
+package request
+
+import (
+	"fmt"
+	"net/url"
+)
+
+const (
+	filterKeyFmt    = "filter[%s]"
+	pageNumberKey   = "page[number]"
+	pageSizeKey     = "page[size]"
+	parseQueryError = "failed parsing query: %v"
+)
+
+// Query builds the query parameters of a request. Every method returns the
+// same Query so calls can be chained.
+type Query struct {
+	values url.Values
+}
+
+func NewQuery() *Query {
+	return &Query{values: url.Values{}}
+}
+
+// ParseQuery returns a Query with the parameters of an already encoded query.
+func ParseQuery(rawQuery string) (*Query, error) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf(parseQueryError, err)
+	}
+	return &Query{values: values}, nil
+}
+
+// Add appends the value to the parameter key.
+func (q *Query) Add(parameterKey, parameterValue string) *Query {
+	q.values.Add(parameterKey, parameterValue)
+	return q
+}
+
+// AddFilter adds "filter[name]" with the value. Empty values are ignored.
+func (q *Query) AddFilter(name, value string) *Query {
+	if value == "" {
+		return q
+	}
+	return q.Add(fmt.Sprintf(filterKeyFmt, name), value)
+}
+
+// SetPage sets "page[number]" and "page[size]", replacing any previous value.
+func (q *Query) SetPage(number, size int) *Query {
+	q.values.Set(pageNumberKey, fmt.Sprint(number))
+	q.values.Set(pageSizeKey, fmt.Sprint(size))
+	return q
+}
+
+// SetPageNumber sets "page[number]", replacing any previous value.
+func (q *Query) SetPageNumber(number string) *Query {
+	q.values.Set(pageNumberKey, number)
+	return q
+}
+
+// Get returns the first value of the parameter key, or empty if there is none.
+func (q *Query) Get(parameterKey string) string {
+	return q.values.Get(parameterKey)
+}
+
+// PageNumber returns the value of "page[number]", or empty if there is none.
+func (q *Query) PageNumber() string {
+	return q.Get(pageNumberKey)
+}
+
+// PageSize returns the value of "page[size]", or empty if there is none.
+func (q *Query) PageSize() string {
+	return q.Get(pageSizeKey)
+}
+
+// Encode returns the parameters in URL encoded form sorted by key.
+func (q *Query) Encode() string {
+	return q.values.Encode()
+}
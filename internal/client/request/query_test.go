@@ -0,0 +1,60 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var queryTest *Query
+
+type TSQuery struct{ suite.Suite }
+
+func TestRunTSQuery(t *testing.T) {
+	suite.Run(t, new(TSQuery))
+}
+
+func (ts *TSQuery) BeforeTest(_, _ string) {
+	queryTest = NewQuery()
+	ts.IsType(&Query{}, queryTest)
+}
+
+func (ts *TSQuery) TestAddSeveralValuesToSameKey() {
+	queryTest.Add("fakeKey", "a").Add("fakeKey", "b")
+	ts.Equal("fakeKey=a&fakeKey=b", queryTest.Encode())
+}
+
+func (ts *TSQuery) TestAddFilterSetCorrectKey() {
+	queryTest.AddFilter("bank_id", "400300")
+	ts.Equal("400300", queryTest.Get("filter[bank_id]"))
+}
+
+func (ts *TSQuery) TestAddFilterIgnoresEmptyValue() {
+	queryTest.AddFilter("bank_id", "")
+	ts.Empty(queryTest.Encode())
+}
+
+func (ts *TSQuery) TestSetPageReplacesPreviousValues() {
+	queryTest.SetPage(0, 100).SetPage(3, 20)
+	ts.Equal("3", queryTest.PageNumber())
+	ts.Equal("20", queryTest.PageSize())
+}
+
+func (ts *TSQuery) TestSetPageNumberKeepsPageSize() {
+	queryTest.SetPage(0, 100).SetPageNumber("1")
+	ts.Equal("1", queryTest.PageNumber())
+	ts.Equal("100", queryTest.PageSize())
+}
+
+func (ts *TSQuery) TestParseQueryReturnsCorrectValues() {
+	query, err := ParseQuery("page%5Bnumber%5D=2&filter%5Bcountry%5D=GB")
+	ts.NoError(err)
+	ts.Equal("2", query.PageNumber())
+	ts.Equal("GB", query.Get("filter[country]"))
+}
+
+func (ts *TSQuery) TestParseInvalidQueryReturnsError() {
+	query, err := ParseQuery("page=%zz")
+	ts.ErrorContains(err, "failed parsing query")
+	ts.Nil(query)
+}
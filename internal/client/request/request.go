@@ -2,6 +2,8 @@ package request
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
@@ -9,6 +11,8 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const (
@@ -18,45 +22,72 @@ const (
 	ACCEPT_ENCODING_KEY   = "Accept-Encoding"
 	CONTENT_TYPE_KEY      = "Content-Type"
 	CONTENT_LENGTH_KEY    = "Content-Length"
+	CONTENT_ENCODING_KEY  = "Content-Encoding"
 	DIGEST_KEY            = "Digest"
+	IDEMPOTENCY_KEY       = "Idempotency-Key"
 	CONTENT_TYPE_VALUE    = "application/vnd.api+json"
-	ACCEPT_ENCODING_VALUE = "gzip"
+	ACCEPT_ENCODING_VALUE = "gzip, deflate"
+	GZIP_ENCODING_VALUE   = "gzip"
 	desireFmt             = "sha-256=%s"
 )
 
+// RequestHandler builds the requests to the API. It holds no state of the
+// requests built, so it is safe for concurrent use.
 type RequestHandler struct {
-	rawData []byte
-	body    io.ReadCloser
+	compressionThreshold int
+}
+
+// payload is the body of a request, as sent.
+type payload struct {
+	rawData    []byte
+	compressed bool
 }
 
-func NewRequestHandler() *RequestHandler {
-	return &RequestHandler{}
+// NewRequestHandler returns a RequestHandler that compresses with gzip the bodies
+// of at least compressionThreshold bytes. Zero means the bodies are not compressed.
+func NewRequestHandler(compressionThreshold int) *RequestHandler {
+	return &RequestHandler{compressionThreshold: compressionThreshold}
 }
 
-func (r *RequestHandler) Request(data interface{}, method, url, host string) (*http.Request, error) {
-	r.setRawDataAndBody(data)
+func (r *RequestHandler) Request(ctx context.Context, data interface{}, method, url,
+	host string) (*http.Request, error) {
+	body := r.payload(data)
 
-	request, err := http.NewRequest(method, url, r.body)
+	request, err := http.NewRequestWithContext(ctx, method, url, r.dataToBody(body))
 	if err != nil {
 		return nil, err
 	}
 
-	r.addHeaders(host, request)
+	r.addHeaders(host, request, body)
 
 	return request, nil
 }
 
-func (r *RequestHandler) SetQuery(request *http.Request, parameterKey, parameterValue string) {
-	query := request.URL.Query()
-	query.Add(parameterKey, parameterValue)
-	request.URL.RawQuery = query.Encode()
+func (r *RequestHandler) SetQuery(request *http.Request, query *Query) {
+	if query == nil {
+		return
+	}
+
+	requestQuery := request.URL.Query()
+	for parameterKey, parameterValues := range query.values {
+		for _, parameterValue := range parameterValues {
+			requestQuery.Add(parameterKey, parameterValue)
+		}
+	}
+	request.URL.RawQuery = requestQuery.Encode()
 }
 
-func (r *RequestHandler) setRawDataAndBody(data interface{}) {
-	if data != nil {
-		r.rawData = r.dataToBytes(data)
-		r.body = r.dataToBody()
+// payload returns the body to send for the data, or nil if there is no data.
+func (r *RequestHandler) payload(data interface{}) *payload {
+	if data == nil {
+		return nil
 	}
+
+	body := &payload{rawData: r.dataToBytes(data)}
+	if r.compressionThreshold > 0 && len(body.rawData) >= r.compressionThreshold {
+		body.rawData, body.compressed = r.compress(body.rawData)
+	}
+	return body
 }
 
 func (r *RequestHandler) dataToBytes(data interface{}) []byte {
@@ -67,15 +98,39 @@ func (r *RequestHandler) dataToBytes(data interface{}) []byte {
 	return dataBytes
 }
 
-func (r *RequestHandler) dataToBody() io.ReadCloser {
-	return io.NopCloser(bytes.NewBuffer(r.rawData))
+// compress returns the data compressed with gzip and true, or the same data and
+// false if it cannot be compressed.
+func (r *RequestHandler) compress(data []byte) ([]byte, bool) {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	if _, err := writer.Write(data); err != nil {
+		return data, false
+	}
+	if err := writer.Close(); err != nil {
+		return data, false
+	}
+	return buffer.Bytes(), true
 }
 
-func (r *RequestHandler) addHeaders(host string, request *http.Request) {
+// dataToBody returns a bytes.Reader so the request gets GetBody set, and the body
+// can be sent again, byte-identical, when the request is retried. It returns nil
+// if there is no body.
+func (r *RequestHandler) dataToBody(body *payload) io.Reader {
+	if body == nil {
+		return nil
+	}
+	return bytes.NewReader(body.rawData)
+}
+
+func (r *RequestHandler) addHeaders(host string, request *http.Request, body *payload) {
 	r.addRequiredHeader(host, request)
 
-	if r.body != nil {
-		r.addHeaderToRequestWithBody(request)
+	if body != nil {
+		r.addHeaderToRequestWithBody(request, body)
+	}
+
+	if !idempotentMethod(request.Method) {
+		r.addIdempotencyKey(request)
 	}
 }
 
@@ -86,15 +141,25 @@ func (r *RequestHandler) addRequiredHeader(host string, request *http.Request) {
 	request.Header.Add(ACCEPT_ENCODING_KEY, ACCEPT_ENCODING_VALUE)
 }
 
-func (r *RequestHandler) addHeaderToRequestWithBody(request *http.Request) {
+func (r *RequestHandler) addHeaderToRequestWithBody(request *http.Request, body *payload) {
 	request.Header.Add(CONTENT_TYPE_KEY, CONTENT_TYPE_VALUE)
-	request.Header.Add(CONTENT_LENGTH_KEY, fmt.Sprint(len(r.rawData)))
-	request.Header.Add(DIGEST_KEY, r.digestFormatted())
+	request.Header.Add(CONTENT_LENGTH_KEY, fmt.Sprint(len(body.rawData)))
+	request.Header.Add(DIGEST_KEY, r.digestFormatted(body.rawData))
+	if body.compressed {
+		request.Header.Add(CONTENT_ENCODING_KEY, GZIP_ENCODING_VALUE)
+	}
 }
 
-func (r *RequestHandler) digestFormatted() string {
+// addIdempotencyKey adds a new key to the request. Retries are copies of the
+// request, so every attempt of the same call sends the same key and the API can
+// tell a retry from a new call.
+func (r *RequestHandler) addIdempotencyKey(request *http.Request) {
+	request.Header.Add(IDEMPOTENCY_KEY, uuid.NewString())
+}
+
+func (r *RequestHandler) digestFormatted(rawData []byte) string {
 	hash := sha256.New()
-	hash.Write(r.rawData)
+	hash.Write(rawData)
 	hashBytes := hash.Sum(nil)
 	desire := fmt.Sprintf(desireFmt, base64.StdEncoding.EncodeToString(hashBytes))
 	return desire
@@ -103,3 +168,14 @@ func (r *RequestHandler) digestFormatted() string {
 func (r *RequestHandler) nowUTCFormatted() string {
 	return time.Now().UTC().Format(http.TimeFormat)
 }
+
+// idempotentMethod reports whether sending twice a request with the method has
+// the same effect as sending it once (RFC 9110, section 9.2.2).
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -2,10 +2,15 @@ package request
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -21,7 +26,7 @@ const (
 var (
 	requestTest     *RequestHandler
 	dataByteTest, _ = json.Marshal(dataTest)
-	bodyTest        = io.NopCloser(bytes.NewBuffer(dataByteTest))
+	bodyTest        = bytes.NewReader(dataByteTest)
 )
 
 type TSRequest struct{ suite.Suite }
@@ -31,36 +36,49 @@ func TestRunTSRequest(t *testing.T) {
 }
 
 func (ts *TSRequest) BeforeTest(_, _ string) {
-	requestTest = NewRequestHandler()
+	requestTest = NewRequestHandler(0)
 	ts.IsType(&RequestHandler{}, requestTest)
 }
 
 func (ts *TSRequest) TestSetCorrectBody() {
-	requestTest.Request(dataTest, http.MethodGet, requestURLTest, hostTest)
-	body := requestTest.body
-	ts.Equal(bodyTest, body)
+	request, err := requestTest.Request(context.Background(), dataTest, http.MethodGet, requestURLTest, hostTest)
+	ts.NoError(err)
+	body, err := io.ReadAll(request.Body)
+	ts.NoError(err)
+	ts.Equal(dataByteTest, body)
 }
 
 func (ts *TSRequest) TestSetCorrectSize() {
-	requestTest.Request(dataTest, http.MethodGet, requestURLTest, hostTest)
-	size := len(requestTest.rawData)
-	expected := len(dataByteTest)
-	ts.Equal(expected, size)
+	request, err := requestTest.Request(context.Background(), dataTest, http.MethodGet, requestURLTest, hostTest)
+	ts.NoError(err)
+	ts.Equal(int64(len(dataByteTest)), request.ContentLength)
 }
 
 func (ts *TSRequest) TestSetCorrectDigest() {
-	requestTest.Request(dataTest, http.MethodGet, requestURLTest, hostTest)
-	desire := requestTest.digestFormatted()
-	ts.Equal(digestExpected, desire)
+	request, err := requestTest.Request(context.Background(), dataTest, http.MethodGet, requestURLTest, hostTest)
+	ts.NoError(err)
+	ts.Equal(digestExpected, request.Header.Get(DIGEST_KEY))
+	ts.Equal(digestExpected, requestTest.digestFormatted(dataByteTest))
 }
+
 func (ts *TSRequest) TestSetNilBodyWhenNoData() {
-	requestTest.Request(nil, http.MethodGet, requestURLTest, hostTest)
-	body := requestTest.body
-	ts.Nil(body)
+	request, err := requestTest.Request(context.Background(), nil, http.MethodGet, requestURLTest, hostTest)
+	ts.NoError(err)
+	ts.Nil(request.Body)
+}
+
+func (ts *TSRequest) TestNoDataAfterDataHasNoBody() {
+	_, err := requestTest.Request(context.Background(), dataTest, http.MethodPost, requestURLTest, hostTest)
+	ts.NoError(err)
+	request, err := requestTest.Request(context.Background(), nil, http.MethodGet, requestURLTest, hostTest)
+	ts.NoError(err)
+	ts.Nil(request.Body)
+	ts.Zero(request.ContentLength)
+	ts.Empty(request.Header.Get(DIGEST_KEY))
 }
 
 func (ts *TSRequest) TestSendValidRequestReturnsNoError() {
-	request, err := requestTest.Request(dataTest, http.MethodPost, requestURLTest, hostTest)
+	request, err := requestTest.Request(context.Background(), dataTest, http.MethodPost, requestURLTest, hostTest)
 	ts.NotNil(request)
 	ts.NoError(err)
 	ts.Equal(hostTest, request.Header.Get(HOST_KEY))
@@ -71,7 +89,7 @@ func (ts *TSRequest) TestSendValidRequestReturnsNoError() {
 }
 
 func (ts *TSRequest) TestSendValidRequestNilDataSetCorrectValues() {
-	request, err := requestTest.Request(nil, http.MethodPost, requestURLTest, hostTest)
+	request, err := requestTest.Request(context.Background(), nil, http.MethodPost, requestURLTest, hostTest)
 	ts.NotNil(request)
 	ts.NoError(err)
 	ts.Equal(hostTest, request.Header.Get(HOST_KEY))
@@ -81,9 +99,24 @@ func (ts *TSRequest) TestSendValidRequestNilDataSetCorrectValues() {
 	ts.Empty(request.Header.Get(DIGEST_KEY))
 }
 func (ts *TSRequest) TestSendValidRequestForDeleteSetCorrectQuery() {
-	request, err := requestTest.Request(nil, http.MethodDelete, requestURLTest, hostTest)
+	request, err := requestTest.Request(context.Background(), nil, http.MethodDelete, requestURLTest, hostTest)
 	ts.NoError(err)
-	requestTest.SetQuery(request, "fakeKey", "fakeValue")
+	requestTest.SetQuery(request, NewQuery().Add("fakeKey", "fakeValue"))
+	ts.Equal("fakeKey=fakeValue", request.URL.RawQuery)
+}
+
+func (ts *TSRequest) TestSetQueryWithSeveralParametersSetCorrectQuery() {
+	request, err := requestTest.Request(context.Background(), nil, http.MethodGet, requestURLTest, hostTest)
+	ts.NoError(err)
+	requestTest.SetQuery(request, NewQuery().SetPage(1, 10).AddFilter("country", "GB"))
+	ts.Equal("filter%5Bcountry%5D=GB&page%5Bnumber%5D=1&page%5Bsize%5D=10", request.URL.RawQuery)
+}
+
+func (ts *TSRequest) TestSetNilQueryKeepsQuery() {
+	request, err := requestTest.Request(context.Background(), nil, http.MethodGet, requestURLTest+"?fakeKey=fakeValue",
+		hostTest)
+	ts.NoError(err)
+	requestTest.SetQuery(request, nil)
 	ts.Equal("fakeKey=fakeValue", request.URL.RawQuery)
 }
 
@@ -93,9 +126,9 @@ func (ts *TSRequest) TestDataToBytesReturnsCorrectly() {
 }
 
 func (ts *TSRequest) TestDataToBodyReturnsCorrectly() {
-	requestTest.Request(dataTest, http.MethodGet, requestURLTest, hostTest)
-	actual := requestTest.dataToBody()
+	actual := requestTest.dataToBody(&payload{rawData: dataByteTest})
 	ts.Equal(bodyTest, actual)
+	ts.Nil(requestTest.dataToBody(nil))
 }
 
 func (ts *TSRequest) TestDataToByteInvalid() {
@@ -107,3 +140,108 @@ func (ts *TSRequest) TestNowUTCFormatted() {
 	ts.Contains(requestTest.nowUTCFormatted(), "GMT")
 
 }
+
+func (ts *TSRequest) TestRequestIsBoundToContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, err := requestTest.Request(ctx, nil, http.MethodGet, requestURLTest, hostTest)
+	ts.NoError(err)
+	ts.Equal(ctx, request.Context())
+}
+
+func (ts *TSRequest) TestRequestBodyCanBeRebuiltIdentical() {
+	request, err := requestTest.Request(context.Background(), dataTest, http.MethodPost,
+		requestURLTest, hostTest)
+	ts.NoError(err)
+	ts.Equal(int64(len(dataByteTest)), request.ContentLength)
+	ts.NotNil(request.GetBody)
+
+	firstBody, err := io.ReadAll(request.Body)
+	ts.NoError(err)
+	for i := 0; i < 3; i++ {
+		body, err := request.GetBody()
+		ts.NoError(err)
+		rebuiltBody, err := io.ReadAll(body)
+		ts.NoError(err)
+		ts.Equal(firstBody, rebuiltBody)
+	}
+}
+
+func (ts *TSRequest) TestPostRequestHasIdempotencyKey() {
+	request, err := requestTest.Request(context.Background(), dataTest, http.MethodPost, requestURLTest, hostTest)
+	ts.NoError(err)
+	ts.NotEmpty(request.Header.Get(IDEMPOTENCY_KEY))
+}
+
+func (ts *TSRequest) TestEveryCallHasItsOwnIdempotencyKey() {
+	first, _ := requestTest.Request(context.Background(), dataTest, http.MethodPost, requestURLTest, hostTest)
+	second, _ := requestTest.Request(context.Background(), dataTest, http.MethodPost, requestURLTest, hostTest)
+	ts.NotEqual(first.Header.Get(IDEMPOTENCY_KEY), second.Header.Get(IDEMPOTENCY_KEY))
+}
+
+func (ts *TSRequest) TestIdempotentRequestHasNoIdempotencyKey() {
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		request, err := requestTest.Request(context.Background(), nil, method, requestURLTest, hostTest)
+		ts.NoError(err)
+		ts.Empty(request.Header.Get(IDEMPOTENCY_KEY), method)
+	}
+}
+
+func (ts *TSRequest) TestBodyOverThresholdIsCompressedWithDigestOfSentBytes() {
+	requestTest = NewRequestHandler(1)
+	request, err := requestTest.Request(context.Background(), dataTest, http.MethodPost, requestURLTest, hostTest)
+	ts.NoError(err)
+	ts.Equal(GZIP_ENCODING_VALUE, request.Header.Get(CONTENT_ENCODING_KEY))
+
+	sent, err := io.ReadAll(request.Body)
+	ts.NoError(err)
+	ts.Equal(fmt.Sprint(len(sent)), request.Header.Get(CONTENT_LENGTH_KEY))
+	ts.Equal(int64(len(sent)), request.ContentLength)
+	hash := sha256.Sum256(sent)
+	ts.Equal("sha-256="+base64.StdEncoding.EncodeToString(hash[:]), request.Header.Get(DIGEST_KEY))
+
+	reader, err := gzip.NewReader(bytes.NewReader(sent))
+	ts.NoError(err)
+	decompressed, err := io.ReadAll(reader)
+	ts.NoError(err)
+	ts.Equal(dataByteTest, decompressed)
+}
+
+func (ts *TSRequest) TestBodyUnderThresholdIsNotCompressed() {
+	requestTest = NewRequestHandler(len(dataByteTest) + 1)
+	request, err := requestTest.Request(context.Background(), dataTest, http.MethodPost, requestURLTest, hostTest)
+	ts.NoError(err)
+	ts.Empty(request.Header.Get(CONTENT_ENCODING_KEY))
+	ts.Equal(digestExpected, request.Header.Get(DIGEST_KEY))
+}
+
+func (ts *TSRequest) TestConcurrentRequestsKeepTheirOwnBody() {
+	requestTest = NewRequestHandler(64)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data := map[string]string{"id": fmt.Sprint(i), "padding": string(bytes.Repeat([]byte("x"), i*2))}
+			request, err := requestTest.Request(context.Background(), data, http.MethodPost, requestURLTest, hostTest)
+			ts.NoError(err)
+
+			sent, err := io.ReadAll(request.Body)
+			ts.NoError(err)
+			hash := sha256.Sum256(sent)
+			ts.Equal("sha-256="+base64.StdEncoding.EncodeToString(hash[:]), request.Header.Get(DIGEST_KEY))
+			ts.Equal(fmt.Sprint(len(sent)), request.Header.Get(CONTENT_LENGTH_KEY))
+
+			if request.Header.Get(CONTENT_ENCODING_KEY) == GZIP_ENCODING_VALUE {
+				reader, err := gzip.NewReader(bytes.NewReader(sent))
+				ts.NoError(err)
+				sent, err = io.ReadAll(reader)
+				ts.NoError(err)
+			}
+			var received map[string]string
+			ts.NoError(json.Unmarshal(sent, &received))
+			ts.Equal(data, received)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,112 @@
+package request
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AdanJSuarez/form3/pkg/signer"
+)
+
+// Ref: https://www.api-docs.form3.tech/api/tutorials/getting-started/create-an-api-key/sign-requests
+// Ref: https://datatracker.ietf.org/doc/html/draft-cavage-http-signatures-10
+
+const (
+	AUTHORIZATION_KEY  = "Authorization"
+	REQUEST_TARGET_KEY = "(request-target)"
+
+	signatureFmt      = `Signature keyId="%s",algorithm="%s",headers="%s",signature="%s"`
+	signingLineFmt    = "%s: %s"
+	signErrorFmt      = "failed signing request: %v"
+	missingHeaderFmt  = "failed signing request: missing %s header"
+	requestTargetFmt  = "%s %s"
+	requestBodyHeader = "content-type content-length digest"
+)
+
+// Authenticator adds the authentication to a request before sending it. It is
+// called before every attempt of the request, retries included.
+type Authenticator interface {
+	Authenticate(request *http.Request) error
+}
+
+// SignatureAuthenticator signs the requests following the Form3 HTTP signature
+// scheme, with the signature of the signer.
+type SignatureAuthenticator struct {
+	signer signer.Signer
+}
+
+func NewSignatureAuthenticator(requestSigner signer.Signer) *SignatureAuthenticator {
+	return &SignatureAuthenticator{signer: requestSigner}
+}
+
+/*
+Authenticate sets the Date header to now, so retries are not rejected for being
+old, and the Authorization header with the signature of the request target and
+the host, date, content-type, content-length and digest headers. The last three
+are only signed on requests with body.
+*/
+func (s *SignatureAuthenticator) Authenticate(request *http.Request) error {
+	request.Header.Set(DATE_KEY, time.Now().UTC().Format(http.TimeFormat))
+
+	headers := signedHeaders(request)
+	signingString, err := signingString(request, headers)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256([]byte(signingString))
+	signature, err := s.signer.Sign(digest[:])
+	if err != nil {
+		return fmt.Errorf(signErrorFmt, err)
+	}
+
+	request.Header.Set(AUTHORIZATION_KEY, fmt.Sprintf(signatureFmt, s.signer.KeyID(),
+		signer.Algorithm(s.signer), strings.Join(headers, " "), base64.StdEncoding.EncodeToString(signature)))
+	return nil
+}
+
+func signedHeaders(request *http.Request) []string {
+	headers := []string{REQUEST_TARGET_KEY, "host", "date"}
+	if request.Header.Get(DIGEST_KEY) != "" {
+		headers = append(headers, strings.Fields(requestBodyHeader)...)
+	}
+	return headers
+}
+
+/*
+signingString returns the string signed for the headers of the request, one line
+per header with its lowercase name and value, in the same order. The
+(request-target) is the lowercase method and the path with the query.
+*/
+func signingString(request *http.Request, headers []string) (string, error) {
+	lines := make([]string, 0, len(headers))
+	for _, header := range headers {
+		value, err := headerValue(request, header)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, fmt.Sprintf(signingLineFmt, header, value))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func headerValue(request *http.Request, header string) (string, error) {
+	switch header {
+	case REQUEST_TARGET_KEY:
+		return fmt.Sprintf(requestTargetFmt, strings.ToLower(request.Method), request.URL.RequestURI()), nil
+	case "host":
+		if request.Host != "" {
+			return request.Host, nil
+		}
+		return request.URL.Host, nil
+	}
+
+	value := request.Header.Get(header)
+	if value == "" {
+		return "", fmt.Errorf(missingHeaderFmt, header)
+	}
+	return value, nil
+}
@@ -0,0 +1,173 @@
+package request
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdanJSuarez/form3/pkg/signer"
+	"github.com/stretchr/testify/suite"
+)
+
+const keyIDTest = "75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8"
+
+var (
+	rsaKeyTest, _   = rsa.GenerateKey(rand.Reader, 2048)
+	ecdsaKeyTest, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signatureRegexp = regexp.MustCompile(`^Signature keyId="([^"]+)",algorithm="([^"]+)",headers="([^"]+)",signature="([^"]+)"$`)
+)
+
+type TSSignature struct{ suite.Suite }
+
+func TestRunTSSignature(t *testing.T) {
+	suite.Run(t, new(TSSignature))
+}
+
+func (ts *TSSignature) TestAuthenticateSignsRequestWithRSAKey() {
+	request := ts.signedRequest(rsaPEM(), http.MethodGet, nil)
+	ts.NoError(verifySignature(request, &rsaKeyTest.PublicKey))
+	ts.Contains(request.Header.Get(AUTHORIZATION_KEY), `algorithm="rsa-sha256"`)
+	ts.Contains(request.Header.Get(AUTHORIZATION_KEY), `headers="(request-target) host date"`)
+}
+
+func (ts *TSSignature) TestAuthenticateSignsRequestWithBodyWithECDSAKey() {
+	sec1ECDSA, _ := x509.MarshalECPrivateKey(ecdsaKeyTest)
+	request := ts.signedRequest(pemBlock("EC PRIVATE KEY", sec1ECDSA), http.MethodPost, dataTest)
+	ts.NoError(verifySignature(request, &ecdsaKeyTest.PublicKey))
+	ts.Contains(request.Header.Get(AUTHORIZATION_KEY), `algorithm="ecdsa-sha256"`)
+	ts.Contains(request.Header.Get(AUTHORIZATION_KEY),
+		`headers="(request-target) host date content-type content-length digest"`)
+}
+
+func (ts *TSSignature) TestModifiedRequestFailsVerification() {
+	request := ts.signedRequest(rsaPEM(), http.MethodPost, dataTest)
+	request.Header.Set(DIGEST_KEY, "sha-256=fakeDigest")
+	ts.Error(verifySignature(request, &rsaKeyTest.PublicKey))
+}
+
+func (ts *TSSignature) TestAuthenticateRefreshesDate() {
+	request := ts.signedRequest(rsaPEM(), http.MethodGet, nil)
+	request.Header.Set(DATE_KEY, "Mon, 02 Jan 2006 15:04:05 GMT")
+	ts.NoError(ts.authenticator(rsaPEM()).Authenticate(request))
+	date, err := http.ParseTime(request.Header.Get(DATE_KEY))
+	ts.NoError(err)
+	ts.WithinDuration(time.Now(), date, time.Minute)
+	ts.NoError(verifySignature(request, &rsaKeyTest.PublicKey))
+}
+
+func (ts *TSSignature) TestAuthenticateWithoutRequiredHeaderReturnsError() {
+	request, err := http.NewRequest(http.MethodPost, requestURLTest, nil)
+	ts.NoError(err)
+	request.Header.Set(DIGEST_KEY, "sha-256=fakeDigest")
+	ts.ErrorContains(ts.authenticator(rsaPEM()).Authenticate(request), "missing content-type header")
+}
+
+func (ts *TSSignature) TestSignedRequestIsVerifiedByServer() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := verifySignature(r, &rsaKeyTest.PublicKey); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	request, err := NewRequestHandler(0).Request(context.Background(), dataTest, http.MethodPost,
+		server.URL+"/v1/organisation/accounts?fake=value", server.Listener.Addr().String())
+	ts.NoError(err)
+	ts.NoError(ts.authenticator(rsaPEM()).Authenticate(request))
+
+	response, err := http.DefaultClient.Do(request)
+	ts.NoError(err)
+	defer response.Body.Close()
+	ts.Equal(http.StatusCreated, response.StatusCode)
+}
+
+func (ts *TSSignature) signedRequest(key []byte, method string, data interface{}) *http.Request {
+	request, err := NewRequestHandler(0).Request(context.Background(), data, method,
+		requestURLTest+"?page%5Bnumber%5D=1", hostTest)
+	ts.NoError(err)
+	ts.NoError(ts.authenticator(key).Authenticate(request))
+	return request
+}
+
+func (ts *TSSignature) authenticator(key []byte) *SignatureAuthenticator {
+	pemSigner, err := signer.NewPEMSigner(keyIDTest, key)
+	ts.NoError(err)
+	return NewSignatureAuthenticator(pemSigner)
+}
+
+// verifySignature checks the Authorization header of the request as the Form3
+// API does, with the public key of keyIDTest.
+func verifySignature(request *http.Request, publicKey crypto.PublicKey) error {
+	match := signatureRegexp.FindStringSubmatch(request.Header.Get(AUTHORIZATION_KEY))
+	if match == nil {
+		return fmt.Errorf("invalid authorization header")
+	}
+	if match[1] != keyIDTest {
+		return fmt.Errorf("unknown key ID %s", match[1])
+	}
+
+	signingString, err := signingString(request, strings.Fields(match[3]))
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(signingString))
+	signature, err := base64.StdEncoding.DecodeString(match[4])
+	if err != nil {
+		return err
+	}
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest[:], signature) {
+			return fmt.Errorf("invalid ecdsa signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported key %T", publicKey)
+}
+
+func rsaPEM() []byte {
+	return pemBlock("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKeyTest))
+}
+
+func pemBlock(blockType string, data []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data})
+}
+
+type signerWithoutAlgorithmTest struct{ signer.Signer }
+
+func (ts *TSSignature) TestAuthenticateWithSignerWithoutAlgorithmUsesHS2019() {
+	pemSigner, err := signer.NewPEMSigner(keyIDTest, rsaPEM())
+	ts.NoError(err)
+	request, err := NewRequestHandler(0).Request(context.Background(), nil, http.MethodGet, requestURLTest, hostTest)
+	ts.NoError(err)
+
+	ts.NoError(NewSignatureAuthenticator(signerWithoutAlgorithmTest{pemSigner}).Authenticate(request))
+	ts.Contains(request.Header.Get(AUTHORIZATION_KEY), `algorithm="hs2019"`)
+	ts.NoError(verifySignature(request, &rsaKeyTest.PublicKey))
+}
+
+func (ts *TSSignature) TestAuthenticateWithSignerErrorReturnsError() {
+	request, err := NewRequestHandler(0).Request(context.Background(), nil, http.MethodGet, requestURLTest, hostTest)
+	ts.NoError(err)
+	socketSigner := signer.NewSocketSigner(keyIDTest, "", "unix", "/nonexistent/form3-signer.sock")
+	ts.ErrorContains(NewSignatureAuthenticator(socketSigner).Authenticate(request), "failed signing request")
+}
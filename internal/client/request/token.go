@@ -0,0 +1,208 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ref: https://www.rfc-editor.org/rfc/rfc6749#section-4.4
+
+const (
+	BEARER_FMT           = "Bearer %s"
+	FORM_CONTENT_TYPE    = "application/x-www-form-urlencoded"
+	clientCredentials    = "client_credentials"
+	tokenTimeout         = 30 * time.Second
+	maxRefreshMargin     = 30 * time.Second
+	maxTokenErrorBody    = 1 << 10
+	tokenStatusErrorFmt  = "failed getting token: status code %d: %s"
+	tokenErrorFmt        = "failed getting token: %v"
+	emptyTokenError      = "failed getting token: empty access token"
+	invalidCredentialFmt = "client credentials: %s cannot be empty"
+)
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// tokenRefresh is a request of a new token, shared by every caller waiting for it.
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+/*
+TokenAuthenticator authenticates the requests with a bearer token obtained from
+the token endpoint with the OAuth2 client credentials grant.
+
+The token is cached until shortly before it expires: 30 seconds, or half its
+lifetime if shorter. A token without expires_in is only used by the requests
+waiting for it. Only one token is requested at a time, no matter how many
+requests need it concurrently.
+*/
+type TokenAuthenticator struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+	now          func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+	refresh   *tokenRefresh
+}
+
+func NewTokenAuthenticator(tokenURL, clientID, clientSecret string) (*TokenAuthenticator, error) {
+	credentials := []struct{ name, value string }{
+		{"token URL", tokenURL}, {"client ID", clientID}, {"client secret", clientSecret},
+	}
+	for _, credential := range credentials {
+		if credential.value == "" {
+			return nil, fmt.Errorf(invalidCredentialFmt, credential.name)
+		}
+	}
+
+	return &TokenAuthenticator{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   &http.Client{Timeout: tokenTimeout},
+		now:          time.Now,
+	}, nil
+}
+
+// Authenticate sets the Authorization header with the bearer token.
+func (t *TokenAuthenticator) Authenticate(request *http.Request) error {
+	token, err := t.Token(request.Context())
+	if err != nil {
+		return err
+	}
+	request.Header.Set(AUTHORIZATION_KEY, fmt.Sprintf(BEARER_FMT, token))
+	return nil
+}
+
+/*
+SetHTTPClient makes the tokens be requested with the client, like the rest of the
+requests, instead of with a default one. Every token request is still limited to
+30 seconds.
+*/
+func (t *TokenAuthenticator) SetHTTPClient(client *http.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.httpClient = client
+}
+
+/*
+Refresh forgets the token of the rejected request, if it is still the cached
+one, so the next request gets a new one. Requests rejected with an already
+replaced token do not cause another refresh.
+*/
+func (t *TokenAuthenticator) Refresh(rejected *http.Request) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && rejected.Header.Get(AUTHORIZATION_KEY) == fmt.Sprintf(BEARER_FMT, t.token) {
+		t.token = ""
+	}
+}
+
+/*
+Token returns the cached token or, if there is none or it is about to expire, a
+new one. The new token is requested without the context, so a caller giving up
+does not make the others fail, but every caller stops waiting for it as soon as
+its context is done.
+*/
+func (t *TokenAuthenticator) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	if t.token != "" && t.now().Before(t.refreshAt) {
+		token := t.token
+		t.mu.Unlock()
+		return token, nil
+	}
+
+	refresh := t.refresh
+	if refresh == nil {
+		refresh = &tokenRefresh{done: make(chan struct{})}
+		t.refresh = refresh
+		go t.fetch(refresh, t.httpClient)
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-refresh.done:
+		return refresh.token, refresh.err
+	}
+}
+
+func (t *TokenAuthenticator) fetch(refresh *tokenRefresh, client *http.Client) {
+	requested := t.now()
+	response, err := t.requestToken(client)
+
+	t.mu.Lock()
+	if err == nil {
+		t.token = response.AccessToken
+		t.refreshAt = requested.Add(t.lifetime(response.ExpiresIn))
+	}
+	t.refresh = nil
+	t.mu.Unlock()
+
+	refresh.token, refresh.err = response.AccessToken, err
+	close(refresh.done)
+}
+
+// lifetime returns how long a token that expires in expiresIn seconds is used.
+func (t *TokenAuthenticator) lifetime(expiresIn int64) time.Duration {
+	expiration := time.Duration(expiresIn) * time.Second
+	margin := expiration / 2
+	if margin > maxRefreshMargin {
+		margin = maxRefreshMargin
+	}
+	return expiration - margin
+}
+
+func (t *TokenAuthenticator) requestToken(client *http.Client) (tokenResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenTimeout)
+	defer cancel()
+
+	form := url.Values{"grant_type": []string{clientCredentials}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, t.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf(tokenErrorFmt, err)
+	}
+	request.SetBasicAuth(url.QueryEscape(t.clientID), url.QueryEscape(t.clientSecret))
+	request.Header.Set(CONTENT_TYPE_KEY, FORM_CONTENT_TYPE)
+	request.Header.Set(ACCEPT_KEY, "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf(tokenErrorFmt, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, maxTokenErrorBody))
+		return tokenResponse{}, fmt.Errorf(tokenStatusErrorFmt, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	token := tokenResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return tokenResponse{}, fmt.Errorf(tokenErrorFmt, err)
+	}
+	if token.AccessToken == "" {
+		return tokenResponse{}, fmt.Errorf(emptyTokenError)
+	}
+	return token, nil
+}
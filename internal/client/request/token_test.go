@@ -0,0 +1,188 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	clientIDTest     = "fakeClientID"
+	clientSecretTest = "fakeClientSecret"
+)
+
+type TSToken struct {
+	suite.Suite
+	server    *httptest.Server
+	requests  int32
+	expiresIn int64
+	status    int
+	release   chan struct{}
+	now       time.Time
+	auth      *TokenAuthenticator
+}
+
+func TestRunTSToken(t *testing.T) {
+	suite.Run(t, new(TSToken))
+}
+
+func (ts *TSToken) BeforeTest(_, _ string) {
+	ts.requests = 0
+	ts.expiresIn = 3600
+	ts.status = http.StatusOK
+	ts.release = nil
+	ts.now = time.Date(2022, time.December, 1, 10, 0, 0, 0, time.UTC)
+	ts.server = httptest.NewServer(http.HandlerFunc(ts.tokenHandler))
+
+	auth, err := NewTokenAuthenticator(ts.server.URL, clientIDTest, clientSecretTest)
+	ts.Require().NoError(err)
+	auth.now = func() time.Time { return ts.now }
+	ts.auth = auth
+}
+
+func (ts *TSToken) AfterTest(_, _ string) {
+	ts.server.Close()
+}
+
+func (ts *TSToken) tokenHandler(w http.ResponseWriter, r *http.Request) {
+	number := atomic.AddInt32(&ts.requests, 1)
+	if ts.release != nil {
+		<-ts.release
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != clientIDTest || clientSecret != clientSecretTest ||
+		r.FormValue("grant_type") != "client_credentials" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": "invalid_client"}`)
+		return
+	}
+
+	w.WriteHeader(ts.status)
+	fmt.Fprintf(w, `{"access_token": "token%d", "token_type": "bearer", "expires_in": %d}`, number, ts.expiresIn)
+}
+
+func (ts *TSToken) TestAuthenticateSetsBearerToken() {
+	request := ts.newRequest()
+	ts.NoError(ts.auth.Authenticate(request))
+	ts.Equal("Bearer token1", request.Header.Get(AUTHORIZATION_KEY))
+}
+
+func (ts *TSToken) TestTokenIsCachedUntilShortlyBeforeExpiry() {
+	ts.token("token1")
+	ts.now = ts.now.Add(59 * time.Minute)
+	ts.token("token1")
+	ts.now = ts.now.Add(31 * time.Second)
+	ts.token("token2")
+	ts.Equal(int32(2), atomic.LoadInt32(&ts.requests))
+}
+
+func (ts *TSToken) TestShortLivedTokenIsRefreshedAtHalfItsLifetime() {
+	ts.expiresIn = 20
+	ts.token("token1")
+	ts.now = ts.now.Add(9 * time.Second)
+	ts.token("token1")
+	ts.now = ts.now.Add(time.Second)
+	ts.token("token2")
+}
+
+func (ts *TSToken) TestTokenWithoutExpiryIsNotCached() {
+	ts.expiresIn = 0
+	ts.token("token1")
+	ts.token("token2")
+}
+
+func (ts *TSToken) TestConcurrentCallersShareOneRefresh() {
+	ts.release = make(chan struct{})
+	var wg sync.WaitGroup
+	tokens := make([]string, 50)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = ts.auth.Token(context.Background())
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(ts.release)
+	wg.Wait()
+
+	ts.Equal(int32(1), atomic.LoadInt32(&ts.requests))
+	for _, token := range tokens {
+		ts.Equal("token1", token)
+	}
+}
+
+func (ts *TSToken) TestRefreshForgetsRejectedToken() {
+	request := ts.newRequest()
+	ts.NoError(ts.auth.Authenticate(request))
+	ts.auth.Refresh(request)
+	ts.token("token2")
+
+	ts.auth.Refresh(request)
+	ts.token("token2")
+}
+
+func (ts *TSToken) TestTokenEndpointErrorReturnsError() {
+	ts.status = http.StatusInternalServerError
+	_, err := ts.auth.Token(context.Background())
+	ts.ErrorContains(err, "failed getting token: status code 500")
+
+	ts.auth.clientSecret = "wrongSecret"
+	_, err = ts.auth.Token(context.Background())
+	ts.ErrorContains(err, "status code 401: {\"error\": \"invalid_client\"}")
+}
+
+func (ts *TSToken) TestTokenWithContextDoneStopsWaiting() {
+	ts.release = make(chan struct{})
+	defer close(ts.release)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := ts.auth.Token(ctx)
+	ts.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (ts *TSToken) TestSetHTTPClientRequestsTokensWithIt() {
+	transport := &countingTransport{}
+	ts.auth.SetHTTPClient(&http.Client{Transport: transport})
+
+	ts.token("token1")
+	ts.Equal(int32(1), atomic.LoadInt32(&transport.requests))
+}
+
+func (ts *TSToken) TestNewTokenAuthenticatorWithEmptyValuesReturnsError() {
+	_, err := NewTokenAuthenticator("", clientIDTest, clientSecretTest)
+	ts.ErrorContains(err, "token URL cannot be empty")
+	_, err = NewTokenAuthenticator(ts.server.URL, clientIDTest, "")
+	ts.ErrorContains(err, "client secret cannot be empty")
+}
+
+func (ts *TSToken) token(expected string) {
+	token, err := ts.auth.Token(context.Background())
+	ts.NoError(err)
+	ts.Equal(expected, token)
+}
+
+func (ts *TSToken) newRequest() *http.Request {
+	request, err := http.NewRequest(http.MethodGet, requestURLTest, nil)
+	ts.NoError(err)
+	return request
+}
+
+// countingTransport counts the requests it sends with the default transport.
+type countingTransport struct {
+	requests int32
+}
+
+func (c *countingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.requests, 1)
+	return http.DefaultTransport.RoundTrip(request)
+}
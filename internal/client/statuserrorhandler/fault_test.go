@@ -0,0 +1,135 @@
+package statuserrorhandler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/form3test"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	accountIDFaultTest = "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"
+	accountFaultTest   = `{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+		"organisation_id": "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", "type": "accounts",
+		"attributes": {"country": "GB", "name": ["Jane Doe"]}}}`
+	invalidAccountFaultTest = `{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+		"organisation_id": "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", "type": "accounts",
+		"attributes": {"country": "gb", "name": ["Jane Doe"]}}}`
+)
+
+// TSFaults handles the error responses of the fake API, with faults injected.
+type TSFaults struct {
+	suite.Suite
+	server *form3test.Server
+}
+
+func TestRunFaultsSuite(t *testing.T) {
+	suite.Run(t, new(TSFaults))
+}
+
+func (ts *TSFaults) BeforeTest(_, _ string) {
+	statusHandlerTest = NewStatusErrorHandler(nil)
+	ts.server = form3test.NewServer()
+}
+
+func (ts *TSFaults) AfterTest(_, _ string) {
+	ts.server.Close()
+}
+
+func (ts *TSFaults) get() *http.Response {
+	response, err := http.Get(ts.server.URL + form3test.AccountPath + "/" + accountIDFaultTest)
+	ts.Require().NoError(err)
+	return response
+}
+
+func (ts *TSFaults) create(body string) *http.Response {
+	response, err := http.Post(ts.server.URL+form3test.AccountPath, "application/vnd.api+json",
+		bytes.NewBufferString(body))
+	ts.Require().NoError(err)
+	return response
+}
+
+func (ts *TSFaults) statusError(response *http.Response) *apierror.APIError {
+	returned, err := statusHandlerTest.StatusError(response)
+	ts.Nil(returned)
+	apiError := &apierror.APIError{}
+	ts.Require().True(errors.As(err, &apiError))
+	return apiError
+}
+
+func (ts *TSFaults) TestEveryStatusIsHandled() {
+	for statusCode, sentinel := range map[int]error{
+		http.StatusBadRequest:          apierror.ErrBadRequest,
+		http.StatusUnauthorized:        apierror.ErrUnauthorized,
+		http.StatusForbidden:           apierror.ErrForbidden,
+		http.StatusNotFound:            apierror.ErrNotFound,
+		http.StatusMethodNotAllowed:    apierror.ErrMethodNotAllowed,
+		http.StatusNotAcceptable:       apierror.ErrNotAcceptable,
+		http.StatusConflict:            apierror.ErrConflict,
+		http.StatusTooManyRequests:     apierror.ErrRateLimited,
+		http.StatusInternalServerError: apierror.ErrServerError,
+		http.StatusBadGateway:          apierror.ErrBadGateway,
+		http.StatusServiceUnavailable:  apierror.ErrServiceUnavailable,
+		http.StatusGatewayTimeout:      apierror.ErrGatewayTimeout,
+	} {
+		ts.server.Inject("", "", form3test.Status(statusCode))
+		apiError := ts.statusError(ts.get())
+		ts.Equal(statusCode, apiError.StatusCode)
+		ts.ErrorIs(apiError, sentinel, statusCode)
+		ts.NotEmpty(apiError.Body, statusCode)
+	}
+	ts.Zero(ts.server.PendingFaults())
+}
+
+func (ts *TSFaults) TestUncoveredStatusIsHandled() {
+	ts.server.Inject("", "", form3test.Status(http.StatusTeapot))
+	apiError := ts.statusError(ts.get())
+	ts.Equal(http.StatusTeapot, apiError.StatusCode)
+	ts.ErrorContains(apiError, "uncovered status code for this request")
+}
+
+func (ts *TSFaults) TestRetryAfterIsExposed() {
+	for _, statusCode := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		ts.server.Inject("", "", form3test.RetryAfter(statusCode, "7"))
+		_, err := statusHandlerTest.StatusError(ts.get())
+		retryAfter, ok := err.(interface{ RetryAfter() time.Duration })
+		ts.Require().True(ok, statusCode)
+		ts.Equal(7*time.Second, retryAfter.RetryAfter())
+	}
+}
+
+func (ts *TSFaults) TestMalformedJSONKeepsRawBody() {
+	ts.server.Inject("", "", form3test.MalformedJSON(http.StatusBadRequest))
+	apiError := ts.statusError(ts.get())
+	ts.ErrorIs(apiError, apierror.ErrBadRequest)
+	ts.Empty(apiError.ErrorMessage)
+	ts.Equal(`{"data": {"id": `, string(apiError.Body))
+}
+
+func (ts *TSFaults) TestNotFoundFromAPI() {
+	apiError := ts.statusError(ts.get())
+	ts.ErrorIs(apiError, apierror.ErrNotFound)
+	ts.Equal("record "+accountIDFaultTest+" does not exist", apiError.ErrorMessage)
+}
+
+func (ts *TSFaults) TestDuplicateFromAPI() {
+	ts.create(accountFaultTest).Body.Close()
+	apiError := ts.statusError(ts.create(accountFaultTest))
+	ts.ErrorIs(apiError, apierror.ErrConflict)
+	ts.Equal("Account cannot be created as it violates a duplicate constraint", apiError.ErrorMessage)
+}
+
+func (ts *TSFaults) TestValidationFailuresFromAPI() {
+	apiError := ts.statusError(ts.create(invalidAccountFaultTest))
+	ts.ErrorIs(apiError, apierror.ErrBadRequest)
+	ts.Equal([]apierror.FieldError{{
+		Field:   "country",
+		Rule:    apierror.RulePattern,
+		Message: "data.attributes.country in body should match '^[A-Z]{2}$'",
+	}}, apiError.FieldErrors)
+}
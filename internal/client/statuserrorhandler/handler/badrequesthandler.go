@@ -14,7 +14,7 @@ func NewBadRequestHandler() StatusErrorHandler {
 
 func (b *badRequestHandler) Execute(response *http.Response) error {
 	if response.StatusCode == http.StatusBadRequest {
-		return newCodeMessageError(response.StatusCode, response.Body)
+		return newCodeMessageError(response)
 	}
 	return b.next.Execute(response)
 }
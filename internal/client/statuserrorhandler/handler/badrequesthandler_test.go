@@ -2,10 +2,12 @@ package handler
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
 	"testing"
 
+	"github.com/AdanJSuarez/form3/pkg/apierror"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -49,3 +51,23 @@ func (ts *TSBadRequestHandler) TestNotBadRequestSetUncoveredError() {
 	ts.ErrorContains(err, "status code 602:")
 	ts.ErrorContains(err, uncoveredMessage)
 }
+
+func (ts *TSBadRequestHandler) TestBadRequestWithValidationListHasFieldErrors() {
+	response := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body: io.NopCloser(bytes.NewBuffer([]byte(`{
+			"error_message": "validation failure list:\nvalidation failure list:\nbic in body should match '^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$'\niban in body is required",
+			"error_code": "d0a17902-63ed-4cb6-a8e8-fac5ca31b0b7"
+		}`))),
+	}
+	err := badRequest.Execute(response)
+	ts.ErrorContains(err, "status code 400")
+
+	apiError := &apierror.APIError{}
+	ts.True(errors.As(err, &apiError))
+	ts.Len(apiError.FieldErrors, 2)
+	ts.Equal("bic", apiError.FieldErrors[0].Field)
+	ts.Equal(apierror.RulePattern, apiError.FieldErrors[0].Rule)
+	ts.Equal("iban", apiError.FieldErrors[1].Field)
+	ts.Equal(apierror.RuleRequired, apiError.FieldErrors[1].Rule)
+}
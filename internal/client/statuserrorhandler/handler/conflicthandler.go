@@ -14,7 +14,7 @@ func NewConflictHandler() StatusErrorHandler {
 
 func (c *conflictHandler) Execute(response *http.Response) error {
 	if response.StatusCode == http.StatusConflict {
-		return newCodeMessageError(response.StatusCode, response.Body)
+		return newCodeMessageError(response)
 	}
 	return c.next.Execute(response)
 }
@@ -2,10 +2,12 @@ package handler
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
 	"testing"
 
+	"github.com/AdanJSuarez/form3/pkg/apierror"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -41,10 +43,12 @@ func (ts *TSConflictHandler) TestConflictResponseReturnsCorrectError() {
 	err := conflict.Execute(responseConflict)
 	ts.ErrorContains(err, "status code 409")
 	ts.ErrorContains(err, "errorCode: 4bc0fa5d-231e-43f3-af79-8fc371d95a31")
+	ts.True(errors.Is(err, apierror.ErrConflict))
 }
 
 func (ts *TSConflictHandler) TestNotConflictResponseReturnsUncoveredError() {
 	err := conflict.Execute(responseFake3)
 	ts.ErrorContains(err, "status code 603:")
 	ts.ErrorContains(err, uncoveredMessage)
+	ts.False(errors.Is(err, apierror.ErrConflict))
 }
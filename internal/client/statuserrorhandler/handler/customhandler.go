@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+)
+
+type customHandler struct {
+	statusHandlers map[int]apierror.StatusHandler
+	next           StatusErrorHandler
+}
+
+// NewCustomHandler returns a handler that uses the status handler registered for
+// the status code of the response, if any, before the next handler. It keeps a
+// copy of statusHandlers, so later changes to the map do not affect it.
+func NewCustomHandler(statusHandlers map[int]apierror.StatusHandler) StatusErrorHandler {
+	handlers := make(map[int]apierror.StatusHandler, len(statusHandlers))
+	for statusCode, statusHandler := range statusHandlers {
+		handlers[statusCode] = statusHandler
+	}
+	return &customHandler{statusHandlers: handlers}
+}
+
+func (c *customHandler) Execute(response *http.Response) error {
+	if statusHandler, ok := c.statusHandlers[response.StatusCode]; ok {
+		if err := statusHandler(response); err != nil {
+			return err
+		}
+	}
+	return c.next.Execute(response)
+}
+
+func (c *customHandler) SetNext(next StatusErrorHandler) {
+	c.next = next
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	custom                  StatusErrorHandler
+	errPreconditionTest     = errors.New("fake precondition failed")
+	responsePrecondition    = &http.Response{StatusCode: http.StatusPreconditionFailed}
+	responseUnprocessable   = &http.Response{StatusCode: http.StatusUnprocessableEntity}
+	customStatusHandlerTest = map[int]apierror.StatusHandler{
+		http.StatusPreconditionFailed: func(*http.Response) error {
+			return errPreconditionTest
+		},
+		http.StatusUnprocessableEntity: func(*http.Response) error {
+			return nil
+		},
+	}
+)
+
+type TSCustomHandler struct{ suite.Suite }
+
+func TestRunCustomSuite(t *testing.T) {
+	suite.Run(t, new(TSCustomHandler))
+}
+
+func (ts *TSCustomHandler) BeforeTest(_, _ string) {
+	uncovered := NewUncoveredHandler()
+	custom = NewCustomHandler(customStatusHandlerTest)
+	custom.SetNext(uncovered)
+}
+
+func (ts *TSCustomHandler) TestRegisteredStatusReturnsCustomError() {
+	err := custom.Execute(responsePrecondition)
+	ts.ErrorIs(err, errPreconditionTest)
+}
+
+func (ts *TSCustomHandler) TestRegisteredStatusReturningNilUsesNext() {
+	err := custom.Execute(responseUnprocessable)
+	ts.ErrorContains(err, "status code 422:")
+	ts.ErrorContains(err, uncoveredMessage)
+}
+
+func (ts *TSCustomHandler) TestNotRegisteredStatusUsesNext() {
+	err := custom.Execute(responseFake11)
+	ts.ErrorContains(err, "status code 611:")
+}
+
+func (ts *TSCustomHandler) TestNilStatusHandlersUsesNext() {
+	custom = NewCustomHandler(nil)
+	custom.SetNext(NewUncoveredHandler())
+	err := custom.Execute(responsePrecondition)
+	ts.ErrorContains(err, "status code 412:")
+}
+
+func (ts *TSCustomHandler) TestLaterChangesToStatusHandlersAreIgnored() {
+	statusHandlers := map[int]apierror.StatusHandler{}
+	custom = NewCustomHandler(statusHandlers)
+	custom.SetNext(NewUncoveredHandler())
+	statusHandlers[http.StatusPreconditionFailed] = func(*http.Response) error {
+		return errPreconditionTest
+	}
+
+	err := custom.Execute(responsePrecondition)
+	ts.NotErrorIs(err, errPreconditionTest)
+	ts.ErrorContains(err, "status code 412:")
+}
@@ -1,47 +1,116 @@
 package handler
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
+	"time"
+
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/retry"
 )
 
 const (
-	errorFmt                = "status code %d: %v"
 	errorCodeMessageFmt     = "errorCode: %s - errorMessage: %s"
 	errorTypeDescriptionFmt = "error: %s - errorDescription: %s"
+	// maxErrorBodyBytes limits how much of an error body is read, so a big body
+	// cannot exhaust the memory. The rest is left for drain.Close.
+	maxErrorBodyBytes = 1 << 20
 )
 
-type errorCodeMessage struct {
-	Message string `json:"error_message"`
-	Code    string `json:"error_code"`
-}
-
-type errorTypeDescription struct {
+// errorBody has the fields of both kinds of error body returned by the API.
+type errorBody struct {
+	Message          string `json:"error_message"`
+	Code             string `json:"error_code"`
 	ErrorType        string `json:"error"`
 	ErrorDescription string `json:"error_description"`
 }
 
-func newError(statusCode int, err error) error {
-	return fmt.Errorf(errorFmt, statusCode, err)
+func newError(response *http.Response, err error) error {
+	apiError, _ := newAPIError(response)
+	apiError.Message = err.Error()
+	return apiError
+}
+
+func newTypeDescriptionError(response *http.Response) error {
+	apiError, err := newAPIError(response)
+	if err != nil {
+		apiError.Message = err.Error()
+		return apiError
+	}
+	apiError.Message = fmt.Sprintf(errorTypeDescriptionFmt, apiError.ErrorType, apiError.ErrorDescription)
+	return apiError
 }
 
-func newTypeDescriptionError(statusCode int, body io.ReadCloser) error {
-	dataReturned := errorTypeDescription{}
-	if err := json.NewDecoder(body).Decode(&dataReturned); err != nil {
-		return fmt.Errorf(errorFmt, statusCode, err)
+func newCodeMessageError(response *http.Response) error {
+	apiError, err := newAPIError(response)
+	if err != nil {
+		apiError.Message = err.Error()
+		return apiError
+	}
+	apiError.FieldErrors = parseFieldErrors(apiError.ErrorMessage)
+	message := strings.ReplaceAll(apiError.ErrorMessage, "validation failure list:\n", "")
+	apiError.Message = fmt.Sprintf(errorCodeMessageFmt, apiError.ErrorCode, message)
+	return apiError
+}
+
+// newAPIError returns the APIError of the response with the values of the body,
+// reading up to 1MiB of it. If the body cannot be decoded, it returns the APIError
+// without them and the error.
+func newAPIError(response *http.Response) (*apierror.APIError, error) {
+	apiError := &apierror.APIError{
+		StatusCode: response.StatusCode,
+		RequestID:  response.Header.Get(apierror.RequestIDHeader),
+	}
+	if response.Body == nil {
+		return apiError, io.EOF
+	}
+
+	rawBody, err := io.ReadAll(io.LimitReader(response.Body, maxErrorBodyBytes))
+	if err != nil {
+		return apiError, err
+	}
+	apiError.Body = rawBody
+
+	body := errorBody{}
+	if err := json.NewDecoder(bytes.NewReader(rawBody)).Decode(&body); err != nil {
+		return apiError, err
 	}
-	messageCode := fmt.Sprintf(errorTypeDescriptionFmt, dataReturned.ErrorType, dataReturned.ErrorDescription)
-	return fmt.Errorf(errorFmt, statusCode, messageCode)
+	apiError.ErrorCode = body.Code
+	apiError.ErrorMessage = body.Message
+	apiError.ErrorType = body.ErrorType
+	apiError.ErrorDescription = body.ErrorDescription
+	return apiError, nil
 }
 
-func newCodeMessageError(statusCode int, body io.ReadCloser) error {
-	dataReturned := errorCodeMessage{}
-	if err := json.NewDecoder(body).Decode(&dataReturned); err != nil {
-		return fmt.Errorf(errorFmt, statusCode, err)
+// retryAfterError keeps the message of err and exposes the delay advised by the
+// server before sending the request again.
+type retryAfterError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+// withRetryAfter returns err with the delay advised in the response headers, if any.
+func withRetryAfter(response *http.Response, err error) error {
+	delay, ok := retry.AdvisedDelay(response.Header, time.Now())
+	if !ok {
+		return err
 	}
-	dataReturned.Message = strings.ReplaceAll(dataReturned.Message, "validation failure list:\n", "")
-	messageCode := fmt.Sprintf(errorCodeMessageFmt, dataReturned.Code, dataReturned.Message)
-	return fmt.Errorf(errorFmt, statusCode, messageCode)
+	return &retryAfterError{err: err, retryAfter: delay}
+}
+
+func (r *retryAfterError) Error() string {
+	return r.err.Error()
+}
+
+// RetryAfter returns the time the server advised to wait before retrying.
+func (r *retryAfterError) RetryAfter() time.Duration {
+	return r.retryAfter
+}
+
+func (r *retryAfterError) Unwrap() error {
+	return r.err
 }
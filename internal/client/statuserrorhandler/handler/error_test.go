@@ -2,10 +2,13 @@ package handler
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"testing"
 
+	"github.com/AdanJSuarez/form3/pkg/apierror"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -32,35 +35,83 @@ func TestRunErrorSuite(t *testing.T) {
 }
 
 func (ts *TSError) TestNewErrorReturnsCorrectly() {
-	err := newError(777, fmt.Errorf("fake error in form3 because I am on the beach :)"))
+	err := newError(&http.Response{StatusCode: 777}, fmt.Errorf("fake error in form3 because I am on the beach :)"))
 	ts.ErrorContains(err, "status code 777:")
 	ts.ErrorContains(err, "I am on the beach")
 }
 
 func (ts *TSError) TestTypeDescriptionErrorReturnsCorrectly() {
-	err := newTypeDescriptionError(777, bodyTypeDescription)
+	err := newTypeDescriptionError(&http.Response{StatusCode: 777, Body: bodyTypeDescription})
 	ts.ErrorContains(err, "status code 777:")
 	ts.ErrorContains(err, "error: invalid_grant")
 	ts.ErrorContains(err, "errorDescription: Wrong email or password.")
 }
 
 func (ts *TSError) TestInvalidBodyNotReturnsTypeDescriptionError() {
-	err := newTypeDescriptionError(777, invalidBody)
+	err := newTypeDescriptionError(&http.Response{StatusCode: 777, Body: invalidBody})
 	ts.ErrorContains(err, "status code 777:")
 	ts.NotContains(err.Error(), "errorDescription:")
 }
 
 func (ts *TSError) TestCodeMessageErrorReturnsCorrectly() {
-	err := newCodeMessageError(777, bodyCodeMessage)
+	err := newCodeMessageError(&http.Response{StatusCode: 777, Body: bodyCodeMessage})
 	ts.ErrorContains(err, "status code 777")
 	ts.ErrorContains(err, "errorCode: d0a17902-63ed-4cb6-a8e8-fac5ca31b0b7")
 	ts.ErrorContains(err, "errorMessage: Message parsing failed: Unexpected character (';' (code 34)): was")
 }
 
 func (ts *TSError) TestInvalidBodyNotReturnsCodeMessageError() {
-	err := newCodeMessageError(777, invalidBody)
+	err := newCodeMessageError(&http.Response{StatusCode: 777, Body: io.NopCloser(bytes.NewBuffer([]byte(invalidData)))})
 	errText := err.Error()
 	ts.ErrorContains(err, "status code 777:")
 	ts.NotContains(errText, "errorCode:")
 	ts.NotContains(errText, "errorMessage:")
 }
+
+func (ts *TSError) TestAPIErrorHasTheResponseValues() {
+	response := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"X-Request-Id": []string{"fakeRequestID"}},
+		Body:       io.NopCloser(bytes.NewBuffer([]byte(dataCodeMessage))),
+	}
+	err := newCodeMessageError(response)
+
+	apiError := &apierror.APIError{}
+	ts.True(errors.As(err, &apiError))
+	ts.Equal(http.StatusBadRequest, apiError.StatusCode)
+	ts.Equal("d0a17902-63ed-4cb6-a8e8-fac5ca31b0b7", apiError.ErrorCode)
+	ts.Contains(apiError.ErrorMessage, "Message parsing failed")
+	ts.Equal("fakeRequestID", apiError.RequestID)
+	ts.Equal(dataCodeMessage, string(apiError.Body))
+	ts.True(errors.Is(err, apierror.ErrBadRequest))
+}
+
+func (ts *TSError) TestAPIErrorHasTypeDescriptionValues() {
+	response := &http.Response{
+		StatusCode: http.StatusForbidden,
+		Body:       io.NopCloser(bytes.NewBuffer([]byte(dataTypeDescription))),
+	}
+	err := newTypeDescriptionError(response)
+
+	apiError := &apierror.APIError{}
+	ts.True(errors.As(err, &apiError))
+	ts.Equal("invalid_grant", apiError.ErrorType)
+	ts.Equal("Wrong email or password.", apiError.ErrorDescription)
+	ts.True(errors.Is(err, apierror.ErrForbidden))
+}
+
+func (ts *TSError) TestErrorWithoutBodyReturnsAPIError() {
+	err := newError(&http.Response{StatusCode: http.StatusNotFound}, fmt.Errorf("fake not found"))
+	ts.EqualError(err, "status code 404: fake not found")
+	ts.True(errors.Is(err, apierror.ErrNotFound))
+}
+
+func (ts *TSError) TestBigBodyIsReadUpToTheLimit() {
+	bigBody := bytes.Repeat([]byte("a"), maxErrorBodyBytes+1024)
+	apiError, err := newAPIError(&http.Response{
+		StatusCode: http.StatusBadGateway,
+		Body:       io.NopCloser(bytes.NewReader(bigBody)),
+	})
+	ts.Error(err)
+	ts.Len(apiError.Body, maxErrorBodyBytes)
+}
@@ -30,17 +30,17 @@ func NewErrorStatusWithoutMessageHandler() StatusErrorHandler {
 func (u *errorStatusWithoutMessageHandler) Execute(response *http.Response) error {
 	switch {
 	case response.StatusCode == http.StatusUnauthorized:
-		return newError(response.StatusCode, fmt.Errorf(unauthorizedMessage))
+		return newError(response, fmt.Errorf(unauthorizedMessage))
 	case response.StatusCode == http.StatusNotAcceptable:
-		return newError(response.StatusCode, fmt.Errorf(notAcceptableMessage))
+		return newError(response, fmt.Errorf(notAcceptableMessage))
 	case response.StatusCode == http.StatusInternalServerError:
-		return newError(response.StatusCode, fmt.Errorf(serverErrorMessage))
+		return newError(response, fmt.Errorf(serverErrorMessage))
 	case response.StatusCode == http.StatusBadGateway:
-		return newError(response.StatusCode, fmt.Errorf(badGatewayMessage))
+		return newError(response, fmt.Errorf(badGatewayMessage))
 	case response.StatusCode == http.StatusServiceUnavailable:
-		return newError(response.StatusCode, fmt.Errorf(serviceUnavailableMessage))
+		return withRetryAfter(response, newError(response, fmt.Errorf(serviceUnavailableMessage)))
 	case response.StatusCode == http.StatusGatewayTimeout:
-		return newError(response.StatusCode, fmt.Errorf(gatewayTimeoutMessage))
+		return newError(response, fmt.Errorf(gatewayTimeoutMessage))
 	}
 	return u.next.Execute(response)
 }
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -85,3 +87,16 @@ func (ts *TSErrorStatusWithoutMessageHandler) TestNotGatewayTimeoutResponseRetur
 	ts.ErrorContains(err, "status code 605:")
 	ts.ErrorContains(err, uncoveredMessage)
 }
+
+func (ts *TSErrorStatusWithoutMessageHandler) TestServiceUnavailableExposesRetryAfter() {
+	response := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{"2"}},
+	}
+	err := errorStatusHandlerTest.Execute(response)
+	ts.ErrorContains(err, "status code 503:")
+
+	var retryAfter interface{ RetryAfter() time.Duration }
+	ts.True(errors.As(err, &retryAfter))
+	ts.Equal(2*time.Second, retryAfter.RetryAfter())
+}
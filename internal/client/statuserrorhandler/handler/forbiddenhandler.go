@@ -14,7 +14,7 @@ func NewForbiddenHandler() StatusErrorHandler {
 
 func (f *forbiddenHandler) Execute(response *http.Response) error {
 	if response.StatusCode == http.StatusForbidden {
-		return newTypeDescriptionError(response.StatusCode, response.Body)
+		return newTypeDescriptionError(response)
 	}
 	return f.next.Execute(response)
 }
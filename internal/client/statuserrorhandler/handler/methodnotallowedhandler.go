@@ -18,7 +18,7 @@ func NewMethodNotAllowedHandler() StatusErrorHandler {
 
 func (m *methodNotAllowedHandler) Execute(response *http.Response) error {
 	if response.StatusCode == http.StatusMethodNotAllowed {
-		return newError(response.StatusCode, fmt.Errorf(methodNotAllowedMessage))
+		return newError(response, fmt.Errorf(methodNotAllowedMessage))
 	}
 	return m.next.Execute(response)
 }
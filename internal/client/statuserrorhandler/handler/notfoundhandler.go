@@ -19,7 +19,7 @@ func NewNotFoundHandler() StatusErrorHandler {
 
 func (n *notFoundHandler) Execute(response *http.Response) error {
 	if response.StatusCode == http.StatusNotFound {
-		return newError(response.StatusCode, fmt.Errorf(notFoundMessage))
+		return newError(response, fmt.Errorf(notFoundMessage))
 	}
 	return n.next.Execute(response)
 }
@@ -20,7 +20,7 @@ func NewTooManyRequestsHandler() StatusErrorHandler {
 
 func (t *tooManyRequestsHandler) Execute(response *http.Response) error {
 	if response.StatusCode == http.StatusTooManyRequests {
-		return newError(response.StatusCode, fmt.Errorf(tooManyRequestsMessage))
+		return withRetryAfter(response, newError(response, fmt.Errorf(tooManyRequestsMessage)))
 	}
 	return t.next.Execute(response)
 }
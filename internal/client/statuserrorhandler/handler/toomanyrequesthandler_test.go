@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -40,3 +42,22 @@ func (ts *TSTooManyRequestHandler) TestNotTooManyRequestResponseReturnsUncovered
 	ts.ErrorContains(err, "status code 611:")
 	ts.ErrorContains(err, uncoveredMessage)
 }
+
+func (ts *TSTooManyRequestHandler) TestTooManyRequestsWithoutAdviceHasNoRetryAfter() {
+	err := tooManyRequests.Execute(responseTooManyRequests)
+	var retryAfter interface{ RetryAfter() time.Duration }
+	ts.False(errors.As(err, &retryAfter))
+}
+
+func (ts *TSTooManyRequestHandler) TestTooManyRequestsExposesRetryAfter() {
+	response := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"7"}},
+	}
+	err := tooManyRequests.Execute(response)
+	ts.ErrorContains(err, "status code 429:")
+
+	var retryAfter interface{ RetryAfter() time.Duration }
+	ts.True(errors.As(err, &retryAfter))
+	ts.Equal(7*time.Second, retryAfter.RetryAfter())
+}
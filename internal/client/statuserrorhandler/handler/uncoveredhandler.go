@@ -15,7 +15,7 @@ func NewUncoveredHandler() StatusErrorHandler {
 }
 
 func (u *uncoveredHandler) Execute(response *http.Response) error {
-	return newError(response.StatusCode, fmt.Errorf(uncoveredMessage))
+	return newError(response, fmt.Errorf(uncoveredMessage))
 }
 
 // SetNext is supposed to not be called in this struct.
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+)
+
+// Ref: https://www.api-docs.form3.tech/api/schemes/fps-direct/introduction/message-body-structure/errors
+
+const (
+	validationFailureList = "validation failure list:"
+	inBodySeparator       = " in body "
+	attributesPrefix      = "data.attributes."
+	dataPrefix            = "data."
+)
+
+// validationRules relates the text of a validation failure with its rule.
+var validationRules = []struct {
+	text string
+	rule string
+}{
+	{"is required", apierror.RuleRequired},
+	{"should match", apierror.RulePattern},
+	{"should be one of", apierror.RuleEnum},
+	{"must be of type", apierror.RuleType},
+	{"should be at most", apierror.RuleMaxLength},
+	{"should be at least", apierror.RuleMinLength},
+	{"should be less than", apierror.RuleMaximum},
+	{"should be greater than", apierror.RuleMinimum},
+	{"should have at most", apierror.RuleMaxItems},
+	{"should have at least", apierror.RuleMinItems},
+}
+
+/*
+parseFieldErrors returns the failures of a validation failure list, like:
+
+	validation failure list:
+	validation failure list:
+	country in body should match '^[A-Z]{2}$'
+	data.attributes.bank_id in body should be at most 11 chars long
+
+Lines that are not about a field in the body are ignored. The field is relative
+to the attributes, or to the data if it is not an attribute.
+*/
+func parseFieldErrors(message string) []apierror.FieldError {
+	if !strings.Contains(message, validationFailureList) {
+		return nil
+	}
+
+	fieldErrors := []apierror.FieldError{}
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		field, failure, found := strings.Cut(line, inBodySeparator)
+		if !found || field == "" {
+			continue
+		}
+		fieldErrors = append(fieldErrors, apierror.FieldError{
+			Field:   fieldName(field),
+			Rule:    rule(failure),
+			Message: line,
+		})
+	}
+	return fieldErrors
+}
+
+func fieldName(field string) string {
+	if strings.HasPrefix(field, attributesPrefix) {
+		return strings.TrimPrefix(field, attributesPrefix)
+	}
+	return strings.TrimPrefix(field, dataPrefix)
+}
+
+func rule(failure string) string {
+	for _, validationRule := range validationRules {
+		if strings.HasPrefix(failure, validationRule.text) {
+			return validationRule.rule
+		}
+	}
+	return apierror.RuleUnknown
+}
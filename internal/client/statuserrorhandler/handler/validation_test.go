@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/stretchr/testify/suite"
+)
+
+const validationMessageTest = "validation failure list:\nvalidation failure list:\n" +
+	"validation failure list:\ncountry in body should match '^[A-Z]{2}$'\n" +
+	"data.attributes.bank_id in body should be at most 11 chars long\n" +
+	"data.attributes.name.0 in body is required\n" +
+	"data.id in body must be of type uuid: \"abc\"\n" +
+	"bic in body is somehow wrong"
+
+type TSValidation struct{ suite.Suite }
+
+func TestRunValidationSuite(t *testing.T) {
+	suite.Run(t, new(TSValidation))
+}
+
+func (ts *TSValidation) TestParseFieldErrorsReturnsEveryField() {
+	expected := []apierror.FieldError{
+		{Field: "country", Rule: apierror.RulePattern, Message: "country in body should match '^[A-Z]{2}$'"},
+		{Field: "bank_id", Rule: apierror.RuleMaxLength,
+			Message: "data.attributes.bank_id in body should be at most 11 chars long"},
+		{Field: "name.0", Rule: apierror.RuleRequired, Message: "data.attributes.name.0 in body is required"},
+		{Field: "id", Rule: apierror.RuleType, Message: "data.id in body must be of type uuid: \"abc\""},
+		{Field: "bic", Rule: apierror.RuleUnknown, Message: "bic in body is somehow wrong"},
+	}
+	ts.Equal(expected, parseFieldErrors(validationMessageTest))
+}
+
+func (ts *TSValidation) TestParseFieldErrorsWithoutValidationListReturnsNil() {
+	ts.Nil(parseFieldErrors("Message parsing failed: country in body should match"))
+}
+
+func (ts *TSValidation) TestParseFieldErrorsIgnoresLinesWithoutField() {
+	fieldErrors := parseFieldErrors("validation failure list:\nsomething went wrong\n in body is required")
+	ts.Empty(fieldErrors)
+	ts.NotNil(fieldErrors)
+}
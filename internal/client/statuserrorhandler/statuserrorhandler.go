@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/AdanJSuarez/form3/internal/client/drain"
 	"github.com/AdanJSuarez/form3/internal/client/statuserrorhandler/handler"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
 )
 
 // Ref: https://refactoring.guru/design-patterns/chain-of-responsibility
@@ -15,18 +17,25 @@ type StatusErrorHandler struct {
 	next handler.StatusErrorHandler
 }
 
-func NewStatusErrorHandler() *StatusErrorHandler {
+// NewStatusErrorHandler returns the chain of handlers. The status handlers are
+// used first, for the status codes they are registered for.
+func NewStatusErrorHandler(statusHandlers map[int]apierror.StatusHandler) *StatusErrorHandler {
 	sh := &StatusErrorHandler{}
 	uncoveredStatus := handler.NewUncoveredHandler()
 	chainOfResponsibilityErrors := sh.chainOfResponsibilityErrors(uncoveredStatus)
-	sh.next = chainOfResponsibilityErrors
+	custom := handler.NewCustomHandler(statusHandlers)
+	custom.SetNext(chainOfResponsibilityErrors)
+	sh.next = custom
 	return sh
 }
 
+// StatusError returns the error of the response. The response is not returned,
+// so its body is closed.
 func (s *StatusErrorHandler) StatusError(response *http.Response) (*http.Response, error) {
 	if response == nil {
 		return nil, fmt.Errorf(nilResponseError)
 	}
+	defer drain.Close(response)
 	return nil, s.next.Execute(response)
 }
 
@@ -1,10 +1,13 @@
 package statuserrorhandler
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
 	"github.com/AdanJSuarez/form3/internal/client/statuserrorhandler/handler"
+	"github.com/AdanJSuarez/form3/internal/leaktest"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -16,6 +19,10 @@ var (
 	responseErrorInternalServerError = &http.Response{
 		StatusCode: http.StatusInternalServerError,
 	}
+	errTeapotTest      = errors.New("fake teapot error")
+	statusHandlersTest = map[int]apierror.StatusHandler{
+		http.StatusTeapot: func(*http.Response) error { return errTeapotTest },
+	}
 )
 
 type TSStatusHandler struct{ suite.Suite }
@@ -25,7 +32,7 @@ func TestRunStatusHandlerSuite(t *testing.T) {
 }
 
 func (ts *TSStatusHandler) BeforeTest(_, _ string) {
-	statusHandlerTest = NewStatusErrorHandler()
+	statusHandlerTest = NewStatusErrorHandler(statusHandlersTest)
 	ts.IsType(new(StatusErrorHandler), statusHandlerTest)
 }
 
@@ -53,3 +60,21 @@ func (ts *TSStatusHandler) TestStatusErrorNilResponseReturnErrorCorrectly() {
 	ts.ErrorContains(err, nilResponseError)
 	ts.Nil(response)
 }
+
+func (ts *TSStatusHandler) TestRegisteredStatusHandlerReturnsItsError() {
+	response, err := statusHandlerTest.StatusError(&http.Response{StatusCode: http.StatusTeapot})
+	ts.ErrorIs(err, errTeapotTest)
+	ts.Nil(response)
+}
+
+func (ts *TSStatusHandler) TestStatusErrorClosesResponseBody() {
+	tracker := leaktest.NewBodyTracker()
+	for _, statusCode := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusTeapot, 777} {
+		_, err := statusHandlerTest.StatusError(&http.Response{
+			StatusCode: statusCode,
+			Body:       tracker.Body(`{"error_message": "fake", "error_code": "fake"} trailing data`),
+		})
+		ts.Error(err)
+	}
+	tracker.AssertAllClosed(ts.T())
+}
@@ -2,26 +2,69 @@ package configuration
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+
+	"github.com/AdanJSuarez/form3/internal/client/httpclient"
+	"github.com/AdanJSuarez/form3/internal/client/request"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/retry"
+	"github.com/AdanJSuarez/form3/pkg/signer"
 )
 
 const (
-	baseURLEnvKey     = "BASE_URL"
-	accountPathEnvKey = "ACCOUNT_PATH"
-	errorEnvFmt       = "failed to get %s from environment variables"
+	baseURLEnvKey      = "BASE_URL"
+	accountPathEnvKey  = "ACCOUNT_PATH"
+	errorEnvFmt        = "failed to get %s from environment variables"
+	statusCodeError    = "status handler: status code %d is not an error status code"
+	nilHandlerError    = "status handler: handler for status code %d is nil"
+	thresholdError     = "compression threshold cannot be negative, got %d"
+	mutateRetriesError = "mutate retries cannot be negative, got %d"
+	nilSignerError     = "signer cannot be nil"
+	initializedError   = "%s must be set before the configuration is initialized"
+
+	defaultMutateRetries = 3
 )
 
 type Configuration struct {
-	baseURL     *url.URL
-	accountPath string
+	baseURL        *url.URL
+	accountPath    string
+	organisationID string
+	retryPolicy    retry.Policy
+	statusHandlers map[int]apierror.StatusHandler
+	// compressionThreshold is the minimum size of the request bodies compressed.
+	compressionThreshold int
+	// mutateRetries is how many times Mutate retries on version conflicts.
+	mutateRetries int
+	authenticator request.Authenticator
+	// profileCredentials is true when the authenticator was set by the profile of
+	// a file, and not by the Set* methods, so the next profile can replace it.
+	profileCredentials bool
+	httpSettings       httpclient.Settings
+	// initialized is true once the base URL and the account path are set, and the
+	// settings used to build the client can no longer change.
+	initialized bool
 }
 
 func New() *Configuration {
-	return &Configuration{}
+	return &Configuration{
+		retryPolicy:    retry.DefaultPolicy(),
+		statusHandlers: map[int]apierror.StatusHandler{},
+		mutateRetries:  defaultMutateRetries,
+	}
 }
 
 func (c *Configuration) InitializeByValue(rawBaseURL, accountPath string) error {
+	if err := c.setLocation(rawBaseURL, accountPath); err != nil {
+		return err
+	}
+
+	c.initialized = true
+	return nil
+}
+
+func (c *Configuration) setLocation(rawBaseURL, accountPath string) error {
 	baseURL, err := c.parseRawBaseURL(rawBaseURL)
 	if err != nil {
 		return err
@@ -29,7 +72,6 @@ func (c *Configuration) InitializeByValue(rawBaseURL, accountPath string) error
 
 	c.baseURL = baseURL
 	c.accountPath = accountPath
-
 	return nil
 }
 
@@ -41,6 +83,145 @@ func (c *Configuration) AccountPath() string {
 	return c.accountPath
 }
 
+// OrganisationID returns the organisation ID of the profile read from a file, or
+// empty if there is none.
+func (c *Configuration) OrganisationID() string {
+	return c.organisationID
+}
+
+func (c *Configuration) RetryPolicy() retry.Policy {
+	return c.retryPolicy
+}
+
+func (c *Configuration) SetRetryPolicy(retryPolicy retry.Policy) error {
+	if err := retryPolicy.Validate(); err != nil {
+		return err
+	}
+
+	c.retryPolicy = retryPolicy
+	return nil
+}
+
+func (c *Configuration) StatusHandlers() map[int]apierror.StatusHandler {
+	return c.statusHandlers
+}
+
+// SetStatusHandler registers the handler for the status code, replacing the
+// previous one if any. Only 4xx and 5xx status codes can be registered, and only
+// before the configuration is initialized.
+func (c *Configuration) SetStatusHandler(statusCode int, statusHandler apierror.StatusHandler) error {
+	if c.initialized {
+		return fmt.Errorf(initializedError, "status handler")
+	}
+	if statusCode < http.StatusBadRequest || statusCode > 599 {
+		return fmt.Errorf(statusCodeError, statusCode)
+	}
+	if statusHandler == nil {
+		return fmt.Errorf(nilHandlerError, statusCode)
+	}
+
+	c.statusHandlers[statusCode] = statusHandler
+	return nil
+}
+
+func (c *Configuration) CompressionThreshold() int {
+	return c.compressionThreshold
+}
+
+// SetCompressionThreshold sets the minimum size, in bytes, of the request bodies
+// compressed with gzip. Zero disables the compression.
+func (c *Configuration) SetCompressionThreshold(threshold int) error {
+	if c.initialized {
+		return fmt.Errorf(initializedError, "compression threshold")
+	}
+	if threshold < 0 {
+		return fmt.Errorf(thresholdError, threshold)
+	}
+
+	c.compressionThreshold = threshold
+	return nil
+}
+
+func (c *Configuration) MutateRetries() int {
+	return c.mutateRetries
+}
+
+// SetMutateRetries sets how many times the account Mutate retries on version
+// conflicts. Zero disables the retries.
+func (c *Configuration) SetMutateRetries(retries int) error {
+	if c.initialized {
+		return fmt.Errorf(initializedError, "mutate retries")
+	}
+	if retries < 0 {
+		return fmt.Errorf(mutateRetriesError, retries)
+	}
+
+	c.mutateRetries = retries
+	return nil
+}
+
+// Authenticator returns the authenticator of the requests, or nil if they are
+// not authenticated.
+func (c *Configuration) Authenticator() request.Authenticator {
+	return c.authenticator
+}
+
+// SetSignatureKey makes every request be signed with the private key in PEM format.
+func (c *Configuration) SetSignatureKey(keyID string, privateKeyPEM []byte) error {
+	if c.initialized {
+		return fmt.Errorf(initializedError, "signature key")
+	}
+	pemSigner, err := signer.NewPEMSigner(keyID, privateKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	return c.SetSigner(pemSigner)
+}
+
+// SetSigner makes every request be signed with the signer.
+func (c *Configuration) SetSigner(requestSigner signer.Signer) error {
+	if c.initialized {
+		return fmt.Errorf(initializedError, "signer")
+	}
+	if requestSigner == nil {
+		return fmt.Errorf(nilSignerError)
+	}
+
+	c.authenticator = request.NewSignatureAuthenticator(requestSigner)
+	return nil
+}
+
+// SetClientCredentials makes every request be authenticated with a bearer token
+// obtained from the token URL with the OAuth2 client credentials grant.
+func (c *Configuration) SetClientCredentials(tokenURL, clientID, clientSecret string) error {
+	if c.initialized {
+		return fmt.Errorf(initializedError, "client credentials")
+	}
+	tokenAuthenticator, err := request.NewTokenAuthenticator(tokenURL, clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+
+	c.authenticator = tokenAuthenticator
+	return nil
+}
+
+func (c *Configuration) HTTPSettings() httpclient.Settings {
+	return c.httpSettings
+}
+
+// SetHTTPSettings sets the settings of the underlying http client, replacing the
+// previous ones. It returns an error if they are not valid.
+func (c *Configuration) SetHTTPSettings(settings httpclient.Settings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	c.httpSettings = settings
+	return nil
+}
+
 func (c *Configuration) InitializeByEnv() error {
 	rawBaseURL, ok := os.LookupEnv(baseURLEnvKey)
 	if !ok {
@@ -51,14 +232,7 @@ func (c *Configuration) InitializeByEnv() error {
 		return fmt.Errorf(errorEnvFmt, accountPathEnvKey)
 	}
 
-	baseURL, err := c.parseRawBaseURL(rawBaseURL)
-	if err != nil {
-		return err
-	}
-
-	c.baseURL = baseURL
-	c.accountPath = accountPath
-	return nil
+	return c.InitializeByValue(rawBaseURL, accountPath)
 }
 
 func (c *Configuration) parseRawBaseURL(rawBaseURL string) (*url.URL, error) {
@@ -1,9 +1,19 @@
 package configuration
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/AdanJSuarez/form3/internal/client/httpclient"
+	"github.com/AdanJSuarez/form3/pkg/retry"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -94,3 +104,131 @@ func (ts *TSConfiguration) TestInvalidInitializedByEnvReturnsErrorOnAccountPath(
 	err := configurationTest.InitializeByEnv()
 	ts.Error(err)
 }
+
+func (ts *TSConfiguration) TestNewSetsDefaultRetryPolicy() {
+	ts.Equal(retry.DefaultPolicy().MaxAttempts, configurationTest.RetryPolicy().MaxAttempts)
+}
+
+func (ts *TSConfiguration) TestSetValidRetryPolicyReturnsNoError() {
+	err := configurationTest.SetRetryPolicy(retry.NoRetries())
+	ts.NoError(err)
+	ts.Equal(1, configurationTest.RetryPolicy().MaxAttempts)
+}
+
+func (ts *TSConfiguration) TestSetInvalidRetryPolicyReturnsError() {
+	err := configurationTest.SetRetryPolicy(retry.Policy{MaxAttempts: -1})
+	ts.ErrorContains(err, "max attempts")
+	ts.Equal(retry.DefaultPolicy().MaxAttempts, configurationTest.RetryPolicy().MaxAttempts)
+}
+
+func (ts *TSConfiguration) TestNewSetsNoStatusHandlers() {
+	ts.Empty(configurationTest.StatusHandlers())
+}
+
+func (ts *TSConfiguration) TestSetStatusHandlerRegistersIt() {
+	errTest := errors.New("fake precondition failed")
+	err := configurationTest.SetStatusHandler(http.StatusPreconditionFailed, func(*http.Response) error {
+		return errTest
+	})
+	ts.NoError(err)
+	ts.Len(configurationTest.StatusHandlers(), 1)
+	ts.ErrorIs(configurationTest.StatusHandlers()[http.StatusPreconditionFailed](nil), errTest)
+}
+
+func (ts *TSConfiguration) TestSetStatusHandlerWithInvalidStatusCodeReturnsError() {
+	for _, statusCode := range []int{http.StatusOK, http.StatusFound, 600} {
+		err := configurationTest.SetStatusHandler(statusCode, func(*http.Response) error { return nil })
+		ts.ErrorContains(err, "is not an error status code")
+	}
+	ts.Empty(configurationTest.StatusHandlers())
+}
+
+func (ts *TSConfiguration) TestSetNilStatusHandlerReturnsError() {
+	err := configurationTest.SetStatusHandler(http.StatusConflict, nil)
+	ts.ErrorContains(err, "is nil")
+	ts.Empty(configurationTest.StatusHandlers())
+}
+
+func (ts *TSConfiguration) TestSetCompressionThreshold() {
+	ts.Zero(configurationTest.CompressionThreshold())
+	ts.NoError(configurationTest.SetCompressionThreshold(1024))
+	ts.Equal(1024, configurationTest.CompressionThreshold())
+}
+
+func (ts *TSConfiguration) TestSetNegativeCompressionThresholdReturnsError() {
+	ts.ErrorContains(configurationTest.SetCompressionThreshold(-1), "cannot be negative")
+	ts.Zero(configurationTest.CompressionThreshold())
+}
+
+func (ts *TSConfiguration) TestSetMutateRetries() {
+	ts.Equal(defaultMutateRetries, configurationTest.MutateRetries())
+	ts.NoError(configurationTest.SetMutateRetries(0))
+	ts.Zero(configurationTest.MutateRetries())
+}
+
+func (ts *TSConfiguration) TestSetNegativeMutateRetriesReturnsError() {
+	ts.ErrorContains(configurationTest.SetMutateRetries(-1), "mutate retries cannot be negative")
+	ts.Equal(defaultMutateRetries, configurationTest.MutateRetries())
+}
+
+func (ts *TSConfiguration) TestSetSignatureKeySetsAuthenticator() {
+	ts.Nil(configurationTest.Authenticator())
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keyBytes, _ := x509.MarshalECPrivateKey(key)
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes})
+
+	ts.NoError(configurationTest.SetSignatureKey("fakeKeyID", privateKeyPEM))
+	ts.NotNil(configurationTest.Authenticator())
+}
+
+func (ts *TSConfiguration) TestSetInvalidSignatureKeyReturnsError() {
+	ts.Error(configurationTest.SetSignatureKey("fakeKeyID", []byte("fake key")))
+	ts.Nil(configurationTest.Authenticator())
+}
+
+func (ts *TSConfiguration) TestSetNilSignerReturnsError() {
+	ts.ErrorContains(configurationTest.SetSigner(nil), "signer cannot be nil")
+	ts.Nil(configurationTest.Authenticator())
+}
+
+func (ts *TSConfiguration) TestSetClientCredentialsSetsAuthenticator() {
+	ts.NoError(configurationTest.SetClientCredentials("https://auth.fakeaddress/token", "fakeID", "fakeSecret"))
+	ts.NotNil(configurationTest.Authenticator())
+}
+
+func (ts *TSConfiguration) TestSetEmptyClientCredentialsReturnsError() {
+	ts.ErrorContains(configurationTest.SetClientCredentials("https://auth.fakeaddress/token", "", "fakeSecret"),
+		"client ID cannot be empty")
+	ts.Nil(configurationTest.Authenticator())
+}
+
+func (ts *TSConfiguration) TestSettersAfterInitializeReturnError() {
+	ts.Require().NoError(configurationTest.InitializeByValue(rawBaseURL, accountPath))
+
+	ts.ErrorContains(configurationTest.SetStatusHandler(http.StatusConflict, func(*http.Response) error { return nil }),
+		"status handler must be set before the configuration is initialized")
+	ts.ErrorContains(configurationTest.SetCompressionThreshold(1024), "before the configuration is initialized")
+	ts.ErrorContains(configurationTest.SetMutateRetries(1), "before the configuration is initialized")
+	ts.ErrorContains(configurationTest.SetSignatureKey("fakeKeyID", []byte("fake key")),
+		"before the configuration is initialized")
+	ts.ErrorContains(configurationTest.SetSigner(nil), "before the configuration is initialized")
+	ts.ErrorContains(configurationTest.SetClientCredentials("https://auth.fakeaddress/token", "fakeID", "fakeSecret"),
+		"before the configuration is initialized")
+	ts.Empty(configurationTest.StatusHandlers())
+	ts.Zero(configurationTest.CompressionThreshold())
+	ts.Equal(defaultMutateRetries, configurationTest.MutateRetries())
+	ts.Nil(configurationTest.Authenticator())
+}
+
+func (ts *TSConfiguration) TestSetHTTPSettings() {
+	ts.Zero(configurationTest.HTTPSettings())
+	settings := httpclient.Settings{Timeout: time.Second, UserAgent: "agent/1.0"}
+	ts.NoError(configurationTest.SetHTTPSettings(settings))
+	ts.Equal(settings, configurationTest.HTTPSettings())
+}
+
+func (ts *TSConfiguration) TestSetInvalidHTTPSettingsReturnsError() {
+	settings := httpclient.Settings{HTTPClient: &http.Client{}, Timeout: time.Second}
+	ts.Error(configurationTest.SetHTTPSettings(settings))
+	ts.Zero(configurationTest.HTTPSettings())
+}
@@ -0,0 +1,267 @@
+package configuration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables overriding the keys of the profile read from a file.
+const (
+	baseURLOverrideEnvKey              = "FORM3_BASE_URL"
+	accountsPathOverrideEnvKey         = "FORM3_PATHS_ACCOUNTS"
+	organisationIDOverrideEnvKey       = "FORM3_ORGANISATION_ID"
+	keyIDOverrideEnvKey                = "FORM3_SIGNATURE_KEY_ID"
+	privateKeyFileOverrideEnvKey       = "FORM3_SIGNATURE_PRIVATE_KEY_FILE"
+	tokenURLOverrideEnvKey             = "FORM3_CLIENT_CREDENTIALS_TOKEN_URL"
+	clientIDOverrideEnvKey             = "FORM3_CLIENT_CREDENTIALS_CLIENT_ID"
+	clientSecretEnvOverrideEnvKey      = "FORM3_CLIENT_CREDENTIALS_CLIENT_SECRET_ENV"
+	timeoutOverrideEnvKey              = "FORM3_HTTP_TIMEOUT"
+	maxConnectionsOverrideEnvKey       = "FORM3_HTTP_MAX_CONNECTIONS"
+	userAgentOverrideEnvKey            = "FORM3_HTTP_USER_AGENT"
+	compressionThresholdOverrideEnvKey = "FORM3_HTTP_COMPRESSION_THRESHOLD"
+)
+
+const (
+	readFileErrorFmt        = "failed reading configuration file: %v"
+	decodeFileErrorFmt      = "failed decoding configuration file %s: %v"
+	fileExtensionErrorFmt   = "configuration file %s must be .yaml, .yml or .json"
+	profileNotFoundFmt      = "profile %q not found in configuration file %s"
+	missingKeyErrorFmt      = "profile %q: %s is required"
+	overrideErrorFmt        = "failed parsing %s: %v"
+	timeoutErrorFmt         = "profile %q: failed parsing http timeout: %v"
+	readKeyErrorFmt         = "profile %q: failed reading private key file: %v"
+	secretEnvErrorFmt       = "profile %q: client secret environment variable %s is not set"
+	bothCredentialsErrorFmt = "profile %q: signature and client credentials cannot be both set"
+	signatureKeysErrorFmt   = "profile %q: signature needs both key_id and private_key_file"
+	setCredentialsErrorFmt  = "profile %q: credentials cannot be set both in the profile and with the options"
+)
+
+type fileConfiguration struct {
+	Profiles map[string]profile `yaml:"profiles" json:"profiles"`
+}
+
+// profile holds the configuration of an environment. The credentials are only
+// references to the secrets: the file of the private key and the environment
+// variable of the client secret.
+type profile struct {
+	BaseURL        string      `yaml:"base_url" json:"base_url"`
+	Paths          paths       `yaml:"paths" json:"paths"`
+	OrganisationID string      `yaml:"organisation_id" json:"organisation_id"`
+	Credentials    credentials `yaml:"credentials" json:"credentials"`
+	HTTP           httpTuning  `yaml:"http" json:"http"`
+}
+
+type paths struct {
+	Accounts string `yaml:"accounts" json:"accounts"`
+}
+
+type credentials struct {
+	Signature         signatureCredentials `yaml:"signature" json:"signature"`
+	ClientCredentials clientCredentials    `yaml:"client_credentials" json:"client_credentials"`
+}
+
+type signatureCredentials struct {
+	KeyID          string `yaml:"key_id" json:"key_id"`
+	PrivateKeyFile string `yaml:"private_key_file" json:"private_key_file"`
+}
+
+type clientCredentials struct {
+	TokenURL        string `yaml:"token_url" json:"token_url"`
+	ClientID        string `yaml:"client_id" json:"client_id"`
+	ClientSecretEnv string `yaml:"client_secret_env" json:"client_secret_env"`
+}
+
+type httpTuning struct {
+	Timeout              string `yaml:"timeout" json:"timeout"`
+	MaxConnections       int    `yaml:"max_connections" json:"max_connections"`
+	UserAgent            string `yaml:"user_agent" json:"user_agent"`
+	CompressionThreshold int    `yaml:"compression_threshold" json:"compression_threshold"`
+}
+
+// InitializeFromFile initializes the configuration with the profile of the YAML
+// or JSON file in path, after overriding its keys with the FORM3_* environment
+// variables set. The configuration is left unchanged if it returns an error.
+// The credentials of a previous profile are replaced, but the profile cannot have
+// credentials if they were already set with SetSigner, SetSignatureKey or
+// SetClientCredentials.
+func (c *Configuration) InitializeFromFile(path, profileName string) error {
+	selected, err := readProfile(path, profileName)
+	if err != nil {
+		return err
+	}
+	if err := selected.override(); err != nil {
+		return err
+	}
+
+	candidate := *c
+	candidate.initialized = false
+	if candidate.profileCredentials {
+		candidate.authenticator = nil
+		candidate.profileCredentials = false
+	}
+	if err := candidate.applyProfile(profileName, selected); err != nil {
+		return err
+	}
+
+	candidate.initialized = true
+	*c = candidate
+	return nil
+}
+
+func readProfile(path, profileName string) (profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return profile{}, fmt.Errorf(readFileErrorFmt, err)
+	}
+
+	var file fileConfiguration
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&file)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&file)
+	default:
+		return profile{}, fmt.Errorf(fileExtensionErrorFmt, path)
+	}
+	if err != nil {
+		return profile{}, fmt.Errorf(decodeFileErrorFmt, path, err)
+	}
+
+	selected, ok := file.Profiles[profileName]
+	if !ok {
+		return profile{}, fmt.Errorf(profileNotFoundFmt, profileName, path)
+	}
+	return selected, nil
+}
+
+// override replaces the keys of the profile with the environment variables set.
+func (p *profile) override() error {
+	for _, key := range []struct {
+		envKey string
+		value  *string
+	}{
+		{baseURLOverrideEnvKey, &p.BaseURL},
+		{accountsPathOverrideEnvKey, &p.Paths.Accounts},
+		{organisationIDOverrideEnvKey, &p.OrganisationID},
+		{keyIDOverrideEnvKey, &p.Credentials.Signature.KeyID},
+		{privateKeyFileOverrideEnvKey, &p.Credentials.Signature.PrivateKeyFile},
+		{tokenURLOverrideEnvKey, &p.Credentials.ClientCredentials.TokenURL},
+		{clientIDOverrideEnvKey, &p.Credentials.ClientCredentials.ClientID},
+		{clientSecretEnvOverrideEnvKey, &p.Credentials.ClientCredentials.ClientSecretEnv},
+		{timeoutOverrideEnvKey, &p.HTTP.Timeout},
+		{userAgentOverrideEnvKey, &p.HTTP.UserAgent},
+	} {
+		if value, ok := os.LookupEnv(key.envKey); ok {
+			*key.value = value
+		}
+	}
+
+	for _, key := range []struct {
+		envKey string
+		value  *int
+	}{
+		{maxConnectionsOverrideEnvKey, &p.HTTP.MaxConnections},
+		{compressionThresholdOverrideEnvKey, &p.HTTP.CompressionThreshold},
+	} {
+		rawValue, ok := os.LookupEnv(key.envKey)
+		if !ok {
+			continue
+		}
+		value, err := strconv.Atoi(rawValue)
+		if err != nil {
+			return fmt.Errorf(overrideErrorFmt, key.envKey, err)
+		}
+		*key.value = value
+	}
+	return nil
+}
+
+func (c *Configuration) applyProfile(profileName string, selected profile) error {
+	if selected.BaseURL == "" {
+		return fmt.Errorf(missingKeyErrorFmt, profileName, "base_url")
+	}
+	if selected.Paths.Accounts == "" {
+		return fmt.Errorf(missingKeyErrorFmt, profileName, "paths.accounts")
+	}
+	if err := c.setLocation(selected.BaseURL, selected.Paths.Accounts); err != nil {
+		return err
+	}
+	c.organisationID = selected.OrganisationID
+
+	if err := c.applyCredentials(profileName, selected.Credentials); err != nil {
+		return err
+	}
+	return c.applyHTTPTuning(profileName, selected.HTTP)
+}
+
+func (c *Configuration) applyCredentials(profileName string, creds credentials) error {
+	signature := creds.Signature
+	clientCreds := creds.ClientCredentials
+	hasSignature := signature != signatureCredentials{}
+	hasClientCredentials := clientCreds != clientCredentials{}
+
+	if (hasSignature || hasClientCredentials) && c.authenticator != nil {
+		return fmt.Errorf(setCredentialsErrorFmt, profileName)
+	}
+	c.profileCredentials = hasSignature || hasClientCredentials
+
+	switch {
+	case hasSignature && hasClientCredentials:
+		return fmt.Errorf(bothCredentialsErrorFmt, profileName)
+	case hasSignature:
+		if signature.KeyID == "" || signature.PrivateKeyFile == "" {
+			return fmt.Errorf(signatureKeysErrorFmt, profileName)
+		}
+		privateKeyPEM, err := os.ReadFile(signature.PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf(readKeyErrorFmt, profileName, err)
+		}
+		return c.SetSignatureKey(signature.KeyID, privateKeyPEM)
+	case hasClientCredentials:
+		clientSecret, ok := os.LookupEnv(clientCreds.ClientSecretEnv)
+		if clientCreds.ClientSecretEnv == "" || !ok {
+			return fmt.Errorf(secretEnvErrorFmt, profileName, clientCreds.ClientSecretEnv)
+		}
+		return c.SetClientCredentials(clientCreds.TokenURL, clientCreds.ClientID, clientSecret)
+	}
+	return nil
+}
+
+// applyHTTPTuning sets the tuning keys present in the profile over the current
+// http settings.
+func (c *Configuration) applyHTTPTuning(profileName string, tuning httpTuning) error {
+	settings := c.httpSettings
+	if tuning.Timeout != "" {
+		timeout, err := time.ParseDuration(tuning.Timeout)
+		if err != nil {
+			return fmt.Errorf(timeoutErrorFmt, profileName, err)
+		}
+		settings.Timeout = timeout
+	}
+	if tuning.MaxConnections != 0 {
+		settings.MaxConnections = tuning.MaxConnections
+	}
+	if tuning.UserAgent != "" {
+		settings.UserAgent = tuning.UserAgent
+	}
+	if err := c.SetHTTPSettings(settings); err != nil {
+		return err
+	}
+
+	if tuning.CompressionThreshold != 0 {
+		return c.SetCompressionThreshold(tuning.CompressionThreshold)
+	}
+	return nil
+}
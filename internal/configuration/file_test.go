@@ -0,0 +1,216 @@
+package configuration
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	yamlFileTest = `
+profiles:
+  local:
+    base_url: http://localhost:8080
+    paths:
+      accounts: /v1/organisation/accounts
+  staging:
+    base_url: https://api.staging-form3.tech
+    paths:
+      accounts: /v1/organisation/accounts
+    organisation_id: eb0bd6f5-c3f5-44b2-b677-acd23cdde73c
+    credentials:
+      client_credentials:
+        token_url: https://auth.staging-form3.tech/oauth2/token
+        client_id: fakeClientID
+        client_secret_env: FORM3_TEST_CLIENT_SECRET
+    http:
+      timeout: 10s
+      max_connections: 20
+      user_agent: payments/1.0
+      compression_threshold: 1024
+`
+	jsonFileTest = `{
+  "profiles": {
+    "production": {
+      "base_url": "https://api.form3.tech",
+      "paths": {"accounts": "/v1/organisation/accounts"},
+      "credentials": {
+        "signature": {"key_id": "fakeKeyID", "private_key_file": "%s"}
+      },
+      "http": {"timeout": "5s"}
+    }
+  }
+}`
+)
+
+type TSFile struct {
+	suite.Suite
+	dir string
+}
+
+func TestRunTSFile(t *testing.T) {
+	suite.Run(t, new(TSFile))
+}
+
+func (ts *TSFile) BeforeTest(_, _ string) {
+	ts.dir = ts.T().TempDir()
+	configurationTest = New()
+}
+
+func (ts *TSFile) writeFile(name, content string) string {
+	path := filepath.Join(ts.dir, name)
+	ts.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func (ts *TSFile) writeKey() string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ts.Require().NoError(err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	ts.Require().NoError(err)
+	return ts.writeFile("key.pem", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes})))
+}
+
+func (ts *TSFile) TestYAMLProfile() {
+	path := ts.writeFile("form3.yaml", yamlFileTest)
+
+	ts.NoError(configurationTest.InitializeFromFile(path, "local"))
+	ts.Equal("http://localhost:8080", configurationTest.BaseURL().String())
+	ts.Equal("/v1/organisation/accounts", configurationTest.AccountPath())
+	ts.Empty(configurationTest.OrganisationID())
+	ts.Nil(configurationTest.Authenticator())
+	ts.Zero(configurationTest.HTTPSettings())
+}
+
+func (ts *TSFile) TestYAMLProfileWithCredentialsAndHTTPTuning() {
+	ts.T().Setenv("FORM3_TEST_CLIENT_SECRET", "fakeSecret")
+	path := ts.writeFile("form3.yml", yamlFileTest)
+
+	ts.NoError(configurationTest.InitializeFromFile(path, "staging"))
+	ts.Equal("https://api.staging-form3.tech", configurationTest.BaseURL().String())
+	ts.Equal("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", configurationTest.OrganisationID())
+	ts.NotNil(configurationTest.Authenticator())
+	settings := configurationTest.HTTPSettings()
+	ts.Equal(10*time.Second, settings.Timeout)
+	ts.Equal(20, settings.MaxConnections)
+	ts.Equal("payments/1.0", settings.UserAgent)
+	ts.Equal(1024, configurationTest.CompressionThreshold())
+}
+
+func (ts *TSFile) TestJSONProfileWithSignature() {
+	keyPath := ts.writeKey()
+	path := ts.writeFile("form3.json", fmt.Sprintf(jsonFileTest, keyPath))
+
+	ts.NoError(configurationTest.InitializeFromFile(path, "production"))
+	ts.Equal("https://api.form3.tech", configurationTest.BaseURL().String())
+	ts.NotNil(configurationTest.Authenticator())
+	ts.Equal(5*time.Second, configurationTest.HTTPSettings().Timeout)
+}
+
+func (ts *TSFile) TestEnvironmentOverridesKeys() {
+	ts.T().Setenv(baseURLOverrideEnvKey, "http://localhost:9090")
+	ts.T().Setenv(organisationIDOverrideEnvKey, "fakeOrganisationID")
+	ts.T().Setenv(timeoutOverrideEnvKey, "3s")
+	ts.T().Setenv(maxConnectionsOverrideEnvKey, "7")
+	path := ts.writeFile("form3.yaml", yamlFileTest)
+
+	ts.NoError(configurationTest.InitializeFromFile(path, "local"))
+	ts.Equal("http://localhost:9090", configurationTest.BaseURL().String())
+	ts.Equal("/v1/organisation/accounts", configurationTest.AccountPath())
+	ts.Equal("fakeOrganisationID", configurationTest.OrganisationID())
+	ts.Equal(3*time.Second, configurationTest.HTTPSettings().Timeout)
+	ts.Equal(7, configurationTest.HTTPSettings().MaxConnections)
+}
+
+func (ts *TSFile) TestInvalidIntegerOverrideReturnsError() {
+	ts.T().Setenv(maxConnectionsOverrideEnvKey, "many")
+	path := ts.writeFile("form3.yaml", yamlFileTest)
+
+	ts.ErrorContains(configurationTest.InitializeFromFile(path, "local"), maxConnectionsOverrideEnvKey)
+	ts.Nil(configurationTest.BaseURL())
+}
+
+func (ts *TSFile) TestUnknownProfileReturnsError() {
+	path := ts.writeFile("form3.yaml", yamlFileTest)
+	ts.ErrorContains(configurationTest.InitializeFromFile(path, "production"), `profile "production" not found`)
+}
+
+func (ts *TSFile) TestInvalidFilesReturnError() {
+	ts.ErrorContains(configurationTest.InitializeFromFile(filepath.Join(ts.dir, "none.yaml"), "local"),
+		"failed reading configuration file")
+	ts.ErrorContains(configurationTest.InitializeFromFile(ts.writeFile("form3.toml", ""), "local"),
+		"must be .yaml, .yml or .json")
+	ts.ErrorContains(configurationTest.InitializeFromFile(ts.writeFile("form3.json", "{"), "local"),
+		"failed decoding")
+	unknownKey := "profiles:\n  local:\n    base_ur1: http://localhost:8080\n"
+	ts.ErrorContains(configurationTest.InitializeFromFile(ts.writeFile("form3.yaml", unknownKey), "local"),
+		"failed decoding")
+}
+
+func (ts *TSFile) TestMissingKeysReturnError() {
+	path := ts.writeFile("form3.yaml", "profiles:\n  local:\n    base_url: http://localhost:8080\n")
+	ts.ErrorContains(configurationTest.InitializeFromFile(path, "local"), "paths.accounts is required")
+}
+
+func (ts *TSFile) TestMissingClientSecretReturnsErrorAndKeepsConfiguration() {
+	ts.NoError(configurationTest.InitializeByValue(rawBaseURL, accountPath))
+	path := ts.writeFile("form3.yaml", yamlFileTest)
+
+	ts.ErrorContains(configurationTest.InitializeFromFile(path, "staging"),
+		"FORM3_TEST_CLIENT_SECRET is not set")
+	ts.Equal(rawBaseURL, configurationTest.BaseURL().String())
+	ts.Nil(configurationTest.Authenticator())
+	ts.Zero(configurationTest.HTTPSettings())
+}
+
+func (ts *TSFile) TestBothCredentialsReturnError() {
+	ts.T().Setenv(keyIDOverrideEnvKey, "fakeKeyID")
+	ts.T().Setenv(privateKeyFileOverrideEnvKey, ts.writeKey())
+	ts.T().Setenv("FORM3_TEST_CLIENT_SECRET", "fakeSecret")
+	path := ts.writeFile("form3.yaml", yamlFileTest)
+
+	ts.ErrorContains(configurationTest.InitializeFromFile(path, "staging"), "cannot be both set")
+}
+
+func (ts *TSFile) TestProfileCredentialsWithCredentialsSetReturnError() {
+	ts.T().Setenv("FORM3_TEST_CLIENT_SECRET", "fakeSecret")
+	path := ts.writeFile("form3.yaml", yamlFileTest)
+	ts.NoError(configurationTest.SetClientCredentials("http://localhost/token", "fakeClientID", "fakeSecret"))
+	authenticator := configurationTest.Authenticator()
+
+	ts.ErrorContains(configurationTest.InitializeFromFile(path, "staging"),
+		"credentials cannot be set both in the profile and with the options")
+	ts.Same(authenticator, configurationTest.Authenticator())
+	ts.Nil(configurationTest.BaseURL())
+
+	ts.NoError(configurationTest.InitializeFromFile(path, "local"))
+	ts.Same(authenticator, configurationTest.Authenticator())
+}
+
+func (ts *TSFile) TestProfileCredentialsReplaceTheOnesOfThePreviousProfile() {
+	ts.T().Setenv("FORM3_TEST_CLIENT_SECRET", "fakeSecret")
+	path := ts.writeFile("form3.yaml", yamlFileTest)
+	ts.NoError(configurationTest.InitializeFromFile(path, "staging"))
+	ts.NotNil(configurationTest.Authenticator())
+
+	ts.NoError(configurationTest.InitializeFromFile(path, "staging"))
+	ts.NotNil(configurationTest.Authenticator())
+	ts.NoError(configurationTest.InitializeFromFile(path, "local"))
+	ts.Nil(configurationTest.Authenticator())
+}
+
+func (ts *TSFile) TestIncompleteSignatureReturnsError() {
+	ts.T().Setenv(keyIDOverrideEnvKey, "fakeKeyID")
+	path := ts.writeFile("form3.yaml", yamlFileTest)
+
+	ts.ErrorContains(configurationTest.InitializeFromFile(path, "local"), "needs both key_id and private_key_file")
+}
@@ -0,0 +1,138 @@
+/*
+Package leaktest helps the tests to detect response bodies not closed and
+goroutines not finished, that would leak connections of the HTTP client.
+*/
+package leaktest
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const goroutinesTimeout = 2 * time.Second
+
+// BodyTracker creates response bodies and tracks which of them are closed.
+type BodyTracker struct {
+	mu     sync.Mutex
+	bodies []*Body
+}
+
+func NewBodyTracker() *BodyTracker {
+	return &BodyTracker{}
+}
+
+// Body returns a new tracked body with the data.
+func (b *BodyTracker) Body(data string) *Body {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	body := &Body{reader: bytes.NewReader([]byte(data))}
+	b.bodies = append(b.bodies, body)
+	return body
+}
+
+// Open returns the number of bodies not closed yet.
+func (b *BodyTracker) Open() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	open := 0
+	for _, body := range b.bodies {
+		if !body.Closed() {
+			open++
+		}
+	}
+	return open
+}
+
+// AssertAllClosed fails the test if any body was not closed, or was closed before
+// being read to the end.
+func (b *BodyTracker) AssertAllClosed(t testing.TB) {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, body := range b.bodies {
+		if !body.Closed() {
+			t.Errorf("body %d was not closed", i)
+		} else if !body.Drained() {
+			t.Errorf("body %d was closed without reading it to the end", i)
+		}
+	}
+}
+
+// Body is a response body that records if it has been read to the end and closed.
+type Body struct {
+	mu      sync.Mutex
+	reader  *bytes.Reader
+	closed  bool
+	drained bool
+}
+
+func (b *Body) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+	n, err := b.reader.Read(p)
+	if err == io.EOF || b.reader.Len() == 0 {
+		b.drained = true
+	}
+	return n, err
+}
+
+func (b *Body) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	return nil
+}
+
+func (b *Body) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+func (b *Body) Drained() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.drained || b.reader.Size() == 0
+}
+
+/*
+CheckGoroutines returns a function that fails the test if there are more
+goroutines running than when CheckGoroutines was called. Goroutines need some
+time to finish, so it waits up to 2 seconds before failing.
+
+Example: defer leaktest.CheckGoroutines(t)()
+*/
+func CheckGoroutines(t testing.TB) func() {
+	before := runtime.NumGoroutine()
+
+	return func() {
+		t.Helper()
+		deadline := time.Now().Add(goroutinesTimeout)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				t.Errorf("%d goroutines leaked:\n%s", runtime.NumGoroutine()-before, stacks())
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func stacks() string {
+	buffer := make([]byte, 1<<20)
+	buffer = buffer[:runtime.Stack(buffer, true)]
+	return strings.TrimSpace(string(buffer))
+}
@@ -0,0 +1,58 @@
+package leaktest
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TSLeakTest struct{ suite.Suite }
+
+func TestRunLeakTestSuite(t *testing.T) {
+	suite.Run(t, new(TSLeakTest))
+}
+
+func (ts *TSLeakTest) TestBodyTrackerCountsOpenBodies() {
+	tracker := NewBodyTracker()
+	first := tracker.Body("fake body")
+	tracker.Body("")
+	ts.Equal(2, tracker.Open())
+
+	_, err := io.ReadAll(first)
+	ts.NoError(err)
+	ts.NoError(first.Close())
+	ts.Equal(1, tracker.Open())
+	ts.True(first.Drained())
+}
+
+func (ts *TSLeakTest) TestAssertAllClosedFailsOnOpenBody() {
+	tracker := NewBodyTracker()
+	tracker.Body("fake body")
+	t := &testing.T{}
+	tracker.AssertAllClosed(t)
+	ts.True(t.Failed())
+}
+
+func (ts *TSLeakTest) TestAssertAllClosedFailsOnBodyNotRead() {
+	tracker := NewBodyTracker()
+	ts.NoError(tracker.Body("fake body").Close())
+	t := &testing.T{}
+	tracker.AssertAllClosed(t)
+	ts.True(t.Failed())
+}
+
+func (ts *TSLeakTest) TestReadClosedBodyReturnsError() {
+	body := NewBodyTracker().Body("fake body")
+	ts.NoError(body.Close())
+	_, err := body.Read(make([]byte, 4))
+	ts.ErrorIs(err, io.ErrClosedPipe)
+}
+
+func (ts *TSLeakTest) TestCheckGoroutinesPassesWhenGoroutinesFinish() {
+	check := CheckGoroutines(ts.T())
+	done := make(chan struct{})
+	go func() { <-done }()
+	close(done)
+	check()
+}
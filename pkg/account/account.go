@@ -1,34 +1,72 @@
 package account
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 
 	"github.com/AdanJSuarez/form3/internal/client"
+	"github.com/AdanJSuarez/form3/internal/client/drain"
+	"github.com/AdanJSuarez/form3/internal/client/request"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
 	"github.com/AdanJSuarez/form3/pkg/model"
 )
 
 const (
 	httpResponseNilError = "http response is nil"
 	versionParam         = "version"
+	accountType          = "accounts"
+	versionConflictFmt   = "%w: %v"
+	mutateAttemptsFmt    = "failed mutating account (attempts: %d): %w"
 )
 
 var emptyDataModel = model.DataModel{}
 
+// patchDataModel is the body of an update. The version is always sent, even if
+// it is 0, so the API can check it is the current version of the account.
+type patchDataModel struct {
+	Data patchData `json:"data"`
+}
+
+type patchData struct {
+	ID         string           `json:"id"`
+	Type       string           `json:"type"`
+	Version    int64            `json:"version"`
+	Attributes model.Attributes `json:"attributes"`
+}
+
+// ErrVersionConflict is returned by Update when the version sent is not the
+// current version of the account. The account should be fetched again before retrying.
+var ErrVersionConflict = errors.New("account version conflict")
+
+/*
+Account manages the accounts of the Form3 API. It is safe for concurrent use by
+multiple goroutines, and is meant to be shared: the requests of all of them reuse
+the same connections. The Iterators it returns are not, and must be used by one
+goroutine at a time.
+*/
 type Account struct {
-	client Client
+	client        apiClient
+	mutateRetries int
 }
 
 // New returns a pointer of "Account" initialized.
-func New(config Configuration) *Account {
+func New(config accountConfig) *Account {
 	baseURL := *config.BaseURL()
 	accountPath := config.AccountPath()
 
-	account := &Account{}
+	account := &Account{mutateRetries: config.MutateRetries()}
 	accountURL := account.accountURL(baseURL, accountPath)
-	account.client = client.New(accountURL)
+	account.client = client.New(accountURL, client.Config{
+		RetryPolicy:          config.RetryPolicy(),
+		StatusHandlers:       config.StatusHandlers(),
+		CompressionThreshold: config.CompressionThreshold(),
+		Authenticator:        config.Authenticator(),
+		HTTP:                 config.HTTPSettings(),
+	})
 	return account
 }
 
@@ -39,7 +77,15 @@ It returns an error otherwise.
 For more reference about model.DataModel values, please check form3 API documentation.
 */
 func (a *Account) Create(data model.DataModel) (model.DataModel, error) {
-	response, err := a.client.Post(data)
+	return a.CreateWithContext(context.Background(), data)
+}
+
+/*
+CreateWithContext works as Create but the request is bound to the context: it is
+cancelled, including any wait between retries, as soon as the context is done.
+*/
+func (a *Account) CreateWithContext(ctx context.Context, data model.DataModel) (model.DataModel, error) {
+	response, err := a.client.Post(ctx, data)
 	if err != nil {
 		return emptyDataModel, err
 	}
@@ -56,7 +102,15 @@ It returns an error otherwise.
 For more reference about model.DataModel values and accountID, please check form3 API documentation.
 */
 func (a *Account) Fetch(accountID string) (model.DataModel, error) {
-	response, err := a.client.Get(accountID)
+	return a.FetchWithContext(context.Background(), accountID)
+}
+
+/*
+FetchWithContext works as Fetch but the request is bound to the context: it is
+cancelled, including any wait between retries, as soon as the context is done.
+*/
+func (a *Account) FetchWithContext(ctx context.Context, accountID string) (model.DataModel, error) {
+	response, err := a.client.Get(ctx, accountID)
 	if err != nil {
 		return emptyDataModel, err
 	}
@@ -66,6 +120,121 @@ func (a *Account) Fetch(accountID string) (model.DataModel, error) {
 	return a.decodeResponse(response)
 }
 
+/*
+List returns an Iterator over all the accounts. Pages of pageSize accounts are
+requested lazily while iterating. If pageSize is zero or negative, the default
+page size (100) is used.
+
+For more reference about listing and pagination, please check form3 API documentation.
+*/
+func (a *Account) List(pageSize int) *Iterator {
+	return a.ListByFilterWithContext(context.Background(), Filter{}, pageSize)
+}
+
+/*
+ListWithContext works as List but every page request is bound to the context.
+Once the context is done, Next returns false and Err returns the context error.
+*/
+func (a *Account) ListWithContext(ctx context.Context, pageSize int) *Iterator {
+	return a.ListByFilterWithContext(ctx, Filter{}, pageSize)
+}
+
+/*
+ListByFilter works as List but only iterates over the accounts matching the
+filter. Empty fields of the filter are not sent.
+
+Example: account.ListByFilter(account.Filter{Country: "GB", BankID: "400300"}, 50)
+
+For more reference about filters, please check form3 API documentation.
+*/
+func (a *Account) ListByFilter(filter Filter, pageSize int) *Iterator {
+	return a.ListByFilterWithContext(context.Background(), filter, pageSize)
+}
+
+/*
+ListByFilterWithContext works as ListByFilter but every page request is bound to
+the context. Once the context is done, Next returns false and Err returns the
+context error.
+*/
+func (a *Account) ListByFilterWithContext(ctx context.Context, filter Filter, pageSize int) *Iterator {
+	return newIterator(ctx, a.client, filter, pageSize)
+}
+
+/*
+Update modifies the attributes of an existing account, identified by its ID and
+current version, and returns the updated account values (model.DataModel). Only
+the non empty attributes of changes are sent.
+
+If the version does not match the current version of the account, the error
+returned wraps ErrVersionConflict so it can be checked with errors.Is.
+
+For more reference about model.Attributes, accountID and version, please check form3 API documentation.
+*/
+func (a *Account) Update(accountID string, version int64, changes model.Attributes) (model.DataModel, error) {
+	return a.UpdateWithContext(context.Background(), accountID, version, changes)
+}
+
+/*
+UpdateWithContext works as Update but the request is bound to the context: it is
+cancelled, including any wait between retries, as soon as the context is done.
+*/
+func (a *Account) UpdateWithContext(ctx context.Context, accountID string, version int64,
+	changes model.Attributes) (model.DataModel, error) {
+	dataModel := patchDataModel{
+		Data: patchData{
+			ID:         accountID,
+			Type:       accountType,
+			Version:    version,
+			Attributes: changes,
+		},
+	}
+
+	response, err := a.client.Patch(ctx, accountID, dataModel)
+	if err != nil {
+		return emptyDataModel, a.updateError(err)
+	}
+
+	defer a.closeBody(response)
+
+	return a.decodeResponse(response)
+}
+
+/*
+Mutate fetches the account, applies mutation to its values and updates the
+account with the attributes resulting from it, using the fetched version. If the
+account was modified in between (version conflict), it is fetched again and the
+mutation applied again, up to the number of retries set with the
+form3.WithMutateRetries option (3 by default). It returns the updated account
+values (model.DataModel).
+
+Every request is bound to the context, and the error returned by the mutation,
+if any, is returned without updating the account.
+
+Example:
+
+	account.Mutate(ctx, accountID, func(data *model.Data) error {
+		data.Attributes.Status = "closed"
+		return nil
+	})
+*/
+func (a *Account) Mutate(ctx context.Context, accountID string,
+	mutation func(*model.Data) error) (model.DataModel, error) {
+	var err error
+	attempts := a.mutateRetries + 1
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return emptyDataModel, err
+		}
+
+		var dataModel model.DataModel
+		dataModel, err = a.mutate(ctx, accountID, mutation)
+		if !errors.Is(err, ErrVersionConflict) {
+			return dataModel, err
+		}
+	}
+	return emptyDataModel, fmt.Errorf(mutateAttemptsFmt, attempts, err)
+}
+
 /*
 Delete deletes an account by its ID and version number.
 It returns an error otherwise.
@@ -73,7 +242,16 @@ It returns an error otherwise.
 For more reference about accountID and version, please check form3 API documentation.
 */
 func (a *Account) Delete(accountID string, version int) error {
-	response, err := a.client.Delete(accountID, versionParam, fmt.Sprint(version))
+	return a.DeleteWithContext(context.Background(), accountID, version)
+}
+
+/*
+DeleteWithContext works as Delete but the request is bound to the context: it is
+cancelled, including any wait between retries, as soon as the context is done.
+*/
+func (a *Account) DeleteWithContext(ctx context.Context, accountID string, version int) error {
+	query := request.NewQuery().Add(versionParam, fmt.Sprint(version))
+	response, err := a.client.Delete(ctx, accountID, query)
 	if err != nil {
 		return err
 	}
@@ -88,6 +266,28 @@ func (a *Account) accountURL(baseURL url.URL, accountPath string) url.URL {
 	return baseURL
 }
 
+func (a *Account) mutate(ctx context.Context, accountID string,
+	mutation func(*model.Data) error) (model.DataModel, error) {
+	current, err := a.FetchWithContext(ctx, accountID)
+	if err != nil {
+		return emptyDataModel, err
+	}
+
+	data := current.Data
+	if err := mutation(&data); err != nil {
+		return emptyDataModel, err
+	}
+
+	return a.UpdateWithContext(ctx, accountID, current.Data.Version, data.Attributes)
+}
+
+func (a *Account) updateError(err error) error {
+	if errors.Is(err, apierror.ErrConflict) {
+		return fmt.Errorf(versionConflictFmt, ErrVersionConflict, err)
+	}
+	return err
+}
+
 func (a *Account) decodeResponse(response *http.Response) (model.DataModel, error) {
 	dataModel := model.DataModel{}
 	if response == nil {
@@ -102,7 +302,5 @@ func (a *Account) decodeResponse(response *http.Response) (model.DataModel, erro
 }
 
 func (a *Account) closeBody(response *http.Response) {
-	if response != nil && response.Body != nil {
-		response.Body.Close()
-	}
+	drain.Close(response)
 }
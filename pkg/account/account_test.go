@@ -2,14 +2,19 @@ package account
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"testing"
 
+	"github.com/AdanJSuarez/form3/internal/client/httpclient"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
 	"github.com/AdanJSuarez/form3/pkg/model"
+	"github.com/AdanJSuarez/form3/pkg/retry"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -25,8 +30,8 @@ const (
 
 var (
 	accountTest        *Account
-	clientMock         *MockClient
-	configurationMock  *MockConfiguration
+	clientMock         *mockApiClient
+	configurationMock  *mockAccountConfig
 	baseURLTest, _     = url.Parse(baseURL)
 	dataAttributesTest = model.Attributes{
 		Country:      "GB",
@@ -52,6 +57,8 @@ var (
 	dataModelByte, _ = json.Marshal(dataModelResponse)
 )
 
+type ctxKeyTest struct{}
+
 type TSAccount struct{ suite.Suite }
 
 func TestRunTSAccount(t *testing.T) {
@@ -59,10 +66,16 @@ func TestRunTSAccount(t *testing.T) {
 }
 
 func (ts *TSAccount) BeforeTest(_, _ string) {
-	configurationMock = NewMockConfiguration(ts.T())
+	configurationMock = newMockAccountConfig(ts.T())
 	configurationMock.On("BaseURL").Return(baseURLTest)
 	configurationMock.On("AccountPath").Return(accountPath)
-	clientMock = NewMockClient(ts.T())
+	configurationMock.On("RetryPolicy").Return(retry.DefaultPolicy())
+	configurationMock.On("StatusHandlers").Return(map[int]apierror.StatusHandler{})
+	configurationMock.On("CompressionThreshold").Return(0)
+	configurationMock.On("MutateRetries").Return(3)
+	configurationMock.On("Authenticator").Return(nil)
+	configurationMock.On("HTTPSettings").Return(httpclient.Settings{})
+	clientMock = newMockApiClient(ts.T())
 
 	accountTest = New(configurationMock)
 	ts.IsType(new(Account), accountTest)
@@ -75,7 +88,7 @@ func (ts *TSAccount) TestCreateValidDataModelReturnsNoError() {
 		StatusCode: 201,
 		Body:       io.NopCloser(bytes.NewBuffer(dataModelByte)),
 	}
-	clientMock.On("Post", mock.Anything).Return(res, nil)
+	clientMock.On("Post", mock.Anything, mock.Anything).Return(res, nil)
 
 	data, err := accountTest.Create(dataModelRequest)
 	ts.NoError(err)
@@ -83,7 +96,7 @@ func (ts *TSAccount) TestCreateValidDataModelReturnsNoError() {
 }
 
 func (ts *TSAccount) TestCreateInvalidDataModelReturnsError() {
-	clientMock.On("Post", mock.Anything).Return(nil, fmt.Errorf(statusBadRequestMsg))
+	clientMock.On("Post", mock.Anything, mock.Anything).Return(nil, fmt.Errorf(statusBadRequestMsg))
 
 	data, err := accountTest.Create(model.DataModel{})
 	ts.ErrorContains(err, "status code 400:")
@@ -95,7 +108,7 @@ func (ts *TSAccount) TestCreateWithDecodeErrorReturnsError() {
 		StatusCode: 201,
 		Body:       io.NopCloser(bytes.NewBuffer([]byte("fakeReturnedBodyError"))),
 	}
-	clientMock.On("Post", mock.Anything).Return(res, nil)
+	clientMock.On("Post", mock.Anything, mock.Anything).Return(res, nil)
 
 	data, err := accountTest.Create(dataModelRequest)
 	ts.ErrorContains(err, "invalid character")
@@ -107,7 +120,7 @@ func (ts *TSAccount) TestFetchValidIDReturnsNoError() {
 		StatusCode: 200,
 		Body:       io.NopCloser(bytes.NewBuffer(dataModelByte)),
 	}
-	clientMock.On("Get", mock.Anything).Return(res, nil)
+	clientMock.On("Get", mock.Anything, mock.Anything).Return(res, nil)
 
 	data, err := accountTest.Fetch("fakeID")
 	ts.NoError(err)
@@ -115,7 +128,7 @@ func (ts *TSAccount) TestFetchValidIDReturnsNoError() {
 }
 
 func (ts *TSAccount) TestFetchNotFoundIDReturnsError() {
-	clientMock.On("Get", mock.AnythingOfType("string")).Return(nil,
+	clientMock.On("Get", mock.Anything, mock.AnythingOfType("string")).Return(nil,
 		fmt.Errorf(statusNotFoundMsg))
 
 	data, err := accountTest.Fetch("fakeID")
@@ -128,36 +141,207 @@ func (ts *TSAccount) TestFetchDecodeErrorReturnsError() {
 		StatusCode: 200,
 		Body:       io.NopCloser(bytes.NewBuffer([]byte("fakeReturnedBodyError"))),
 	}
-	clientMock.On("Get", mock.Anything).Return(res, nil)
+	clientMock.On("Get", mock.Anything, mock.Anything).Return(res, nil)
 
 	data, err := accountTest.Fetch("fakeID")
 	ts.ErrorContains(err, "invalid character")
 	ts.Empty(data)
 }
 
+func (ts *TSAccount) TestListReturnsIterator() {
+	iterator := accountTest.List(10)
+	ts.IsType(new(Iterator), iterator)
+	ts.Equal("10", iterator.query.PageSize())
+	ts.Equal("0", iterator.query.PageNumber())
+}
+
+func (ts *TSAccount) TestListByFilterReturnsIteratorWithFilter() {
+	iterator := accountTest.ListByFilter(Filter{BankID: "400300", Country: "GB"}, 10)
+	ts.Equal("400300", iterator.query.Get("filter[bank_id]"))
+	ts.Equal("GB", iterator.query.Get("filter[country]"))
+	ts.Empty(iterator.query.Get("filter[iban]"))
+}
+
+func (ts *TSAccount) TestUpdateValidChangesReturnsNoError() {
+	res := &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(bytes.NewBuffer(dataModelByte)),
+	}
+	expected := patchDataModel{
+		Data: patchData{
+			ID:         uuidTest,
+			Type:       "accounts",
+			Version:    3,
+			Attributes: dataAttributesTest,
+		},
+	}
+	clientMock.On("Patch", mock.Anything, uuidTest, expected).Return(res, nil)
+
+	data, err := accountTest.Update(uuidTest, 3, dataAttributesTest)
+	ts.NoError(err)
+	ts.Equal(dataModelResponse, data)
+}
+
+func (ts *TSAccount) TestUpdateVersionZeroIsSent() {
+	clientMock.On("Patch", mock.Anything, uuidTest, mock.MatchedBy(func(dataModel patchDataModel) bool {
+		body, err := json.Marshal(dataModel)
+		return err == nil && bytes.Contains(body, []byte(`"version":0`))
+	})).Return(dataModelHTTPResponse(dataModelResponse), nil).Once()
+
+	_, err := accountTest.Update(uuidTest, 0, dataAttributesTest)
+	ts.NoError(err)
+}
+
+func (ts *TSAccount) TestUpdateWrongVersionReturnsVersionConflictError() {
+	conflictError := fmt.Errorf("status code 409: errorCode: 12345 - errorMessage: invalid version")
+	clientMock.On("Patch", mock.Anything, mock.Anything, mock.Anything).Return(nil,
+		&apierror.APIError{StatusCode: http.StatusConflict, Message: conflictError.Error()})
+
+	data, err := accountTest.Update(uuidTest, 7, dataAttributesTest)
+	ts.True(errors.Is(err, ErrVersionConflict))
+	ts.ErrorContains(err, "status code 409:")
+	ts.Empty(data)
+}
+
+func (ts *TSAccount) TestUpdateNotFoundAccountReturnsError() {
+	clientMock.On("Patch", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf(statusNotFoundMsg))
+
+	data, err := accountTest.Update(uuidTest, 0, dataAttributesTest)
+	ts.ErrorContains(err, "status code 404:")
+	ts.False(errors.Is(err, ErrVersionConflict))
+	ts.Empty(data)
+}
+
+func (ts *TSAccount) TestUpdateWithDecodeErrorReturnsError() {
+	res := &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(bytes.NewBuffer([]byte("fakeReturnedBodyError"))),
+	}
+	clientMock.On("Patch", mock.Anything, mock.Anything, mock.Anything).Return(res, nil)
+
+	data, err := accountTest.Update(uuidTest, 0, dataAttributesTest)
+	ts.ErrorContains(err, "invalid character")
+	ts.Empty(data)
+}
+
+func (ts *TSAccount) TestWithContextMethodsPassContextToClient() {
+	ctx := context.WithValue(context.Background(), ctxKeyTest{}, "fakeValue")
+	clientMock.On("Post", ctx, mock.Anything).Return(dataModelHTTPResponse(dataModelResponse), nil).Once()
+	clientMock.On("Get", ctx, uuidTest).Return(dataModelHTTPResponse(dataModelResponse), nil).Once()
+	clientMock.On("Patch", ctx, uuidTest, mock.Anything).Return(
+		dataModelHTTPResponse(dataModelResponse), nil).Once()
+	clientMock.On("Delete", ctx, uuidTest, mock.Anything).Return(&http.Response{StatusCode: 204}, nil).Once()
+
+	_, err := accountTest.CreateWithContext(ctx, dataModelRequest)
+	ts.NoError(err)
+	_, err = accountTest.FetchWithContext(ctx, uuidTest)
+	ts.NoError(err)
+	_, err = accountTest.UpdateWithContext(ctx, uuidTest, 0, dataAttributesTest)
+	ts.NoError(err)
+	ts.NoError(accountTest.DeleteWithContext(ctx, uuidTest, 0))
+	ts.Equal(ctx, accountTest.ListWithContext(ctx, 10).ctx)
+	ts.Equal(ctx, accountTest.ListByFilterWithContext(ctx, Filter{}, 10).ctx)
+}
+
+func (ts *TSAccount) TestMutateAppliesMutationAndReturnsNoError() {
+	updated := dataModelResponse
+	updated.Data.Attributes.Status = "closed"
+	clientMock.On("Get", mock.Anything, uuidTest).Return(dataModelHTTPResponse(dataModelResponse), nil).Once()
+	clientMock.On("Patch", mock.Anything, uuidTest, mock.MatchedBy(func(dataModel patchDataModel) bool {
+		return dataModel.Data.Attributes.Status == "closed"
+	})).Return(dataModelHTTPResponse(updated), nil).Once()
+
+	data, err := accountTest.Mutate(context.Background(), uuidTest, closeAccount)
+	ts.NoError(err)
+	ts.Equal(updated, data)
+}
+
+func (ts *TSAccount) TestMutateRetriesOnVersionConflict() {
+	clientMock.On("Get", mock.Anything, uuidTest).Return(dataModelHTTPResponse(dataModelResponse), nil).Once()
+	clientMock.On("Get", mock.Anything, uuidTest).Return(dataModelHTTPResponse(dataModelResponse), nil).Once()
+	clientMock.On("Patch", mock.Anything, mock.Anything, mock.Anything).Return(nil, apierror.ErrConflict).Once()
+	clientMock.On("Patch", mock.Anything, mock.Anything, mock.Anything).Return(
+		dataModelHTTPResponse(dataModelResponse), nil).Once()
+
+	data, err := accountTest.Mutate(context.Background(), uuidTest, closeAccount)
+	ts.NoError(err)
+	ts.Equal(dataModelResponse, data)
+}
+
+func (ts *TSAccount) TestNewSetsMutateRetriesOfConfiguration() {
+	ts.Equal(3, accountTest.mutateRetries)
+}
+
+func (ts *TSAccount) TestMutateReturnsErrorAfterRetries() {
+	accountTest.mutateRetries = 1
+	clientMock.On("Get", mock.Anything, uuidTest).Return(dataModelHTTPResponse(dataModelResponse), nil).Once()
+	clientMock.On("Get", mock.Anything, uuidTest).Return(dataModelHTTPResponse(dataModelResponse), nil).Once()
+	clientMock.On("Patch", mock.Anything, mock.Anything, mock.Anything).Return(nil, apierror.ErrConflict).Twice()
+
+	data, err := accountTest.Mutate(context.Background(), uuidTest, closeAccount)
+	ts.True(errors.Is(err, ErrVersionConflict))
+	ts.ErrorContains(err, "failed mutating account (attempts: 2)")
+	ts.Empty(data)
+}
+
+func (ts *TSAccount) TestMutateWithoutRetriesAttemptsOnce() {
+	accountTest.mutateRetries = 0
+	clientMock.On("Get", mock.Anything, uuidTest).Return(dataModelHTTPResponse(dataModelResponse), nil).Once()
+	clientMock.On("Patch", mock.Anything, mock.Anything, mock.Anything).Return(nil, apierror.ErrConflict).Once()
+
+	data, err := accountTest.Mutate(context.Background(), uuidTest, closeAccount)
+	ts.ErrorIs(err, ErrVersionConflict)
+	ts.ErrorContains(err, "failed mutating account (attempts: 1)")
+	ts.Empty(data)
+}
+
+func (ts *TSAccount) TestMutateWithErrorOnMutationNotUpdates() {
+	clientMock.On("Get", mock.Anything, uuidTest).Return(dataModelHTTPResponse(dataModelResponse), nil).Once()
+
+	data, err := accountTest.Mutate(context.Background(), uuidTest, func(*model.Data) error {
+		return fmt.Errorf("fakeMutationError")
+	})
+	ts.ErrorContains(err, "fakeMutationError")
+	ts.Empty(data)
+}
+
+func (ts *TSAccount) TestMutateWithErrorOnFetchReturnsError() {
+	clientMock.On("Get", mock.Anything, uuidTest).Return(nil, fmt.Errorf(statusNotFoundMsg)).Once()
+
+	data, err := accountTest.Mutate(context.Background(), uuidTest, closeAccount)
+	ts.ErrorContains(err, "status code 404:")
+	ts.Empty(data)
+}
+
+func (ts *TSAccount) TestMutateWithContextDoneReturnsError() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	data, err := accountTest.Mutate(ctx, uuidTest, closeAccount)
+	ts.ErrorIs(err, context.Canceled)
+	ts.Empty(data)
+}
+
 func (ts *TSAccount) TestDeleteValidAccountReturnsNoError() {
 	res := &http.Response{
 		StatusCode: 204,
 		Body:       nil,
 	}
-	clientMock.On("Delete", mock.Anything, mock.Anything,
-		mock.Anything).Return(res, nil)
+	clientMock.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(res, nil)
 
 	err := accountTest.Delete("fakeID", 0)
 	ts.NoError(err)
 }
 
 func (ts *TSAccount) TestDeleteNotFoundAccountReturnsError() {
-	clientMock.On("Delete", mock.Anything, mock.Anything,
-		mock.Anything).Return(nil, fmt.Errorf(statusNotFoundMsg))
+	clientMock.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf(statusNotFoundMsg))
 
 	err := accountTest.Delete("fakeID", 0)
 	ts.ErrorContains(err, "status code 404:")
 }
 
 func (ts *TSAccount) TestDeleteInvalidVersionReturnsError() {
-	clientMock.On("Delete", mock.Anything, mock.Anything,
-		mock.Anything).Return(nil, fmt.Errorf(statusNotFoundMsg))
+	clientMock.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf(statusNotFoundMsg))
 
 	err := accountTest.Delete("fakeID", 7)
 	ts.ErrorContains(err, "status code 404:")
@@ -206,3 +390,16 @@ func (ts *TSAccount) TestCloseBodyNotPanicNilBody() {
 func (ts *TSAccount) TestCloseBodyNotNoPanicNilResponse() {
 	ts.NotPanics(func() { accountTest.closeBody(nil) })
 }
+
+func dataModelHTTPResponse(dataModel model.DataModel) *http.Response {
+	body, _ := json.Marshal(dataModel)
+	return &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(bytes.NewBuffer(body)),
+	}
+}
+
+func closeAccount(data *model.Data) error {
+	data.Attributes.Status = "closed"
+	return nil
+}
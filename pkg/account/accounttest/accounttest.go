@@ -0,0 +1,54 @@
+/*
+Package accounttest provides an in-memory account.AccountService, to test the
+code using the library without the API and without mocks.
+*/
+package accounttest
+
+import (
+	"github.com/AdanJSuarez/form3/internal/client/httpclient"
+	"github.com/AdanJSuarez/form3/internal/configuration"
+	"github.com/AdanJSuarez/form3/pkg/account"
+	"github.com/AdanJSuarez/form3/pkg/form3test"
+	"github.com/AdanJSuarez/form3/pkg/model"
+)
+
+// baseURL is the URL of the requests of Service. They never leave the process.
+const baseURL = "http://accounttest.invalid"
+
+/*
+Service is an in-memory account.AccountService. It is an account.Account whose
+requests are answered from memory by the fake API of the form3test package, so it
+follows the same rules and returns the same errors as account.Account: an
+*apierror.APIError for the errors of the API, that wraps account.ErrVersionConflict
+when Update sends an old version. It is safe for concurrent use.
+
+Example:
+
+	accounts := accounttest.NewService()
+	payments := Payments{accounts: accounts}
+*/
+type Service struct {
+	*account.Account
+	transport *form3test.Transport
+}
+
+var _ account.AccountService = (*Service)(nil)
+
+// NewService returns a Service without accounts.
+func NewService() *Service {
+	transport := form3test.NewTransport()
+	config := configuration.New()
+	// Neither can fail: the settings and the URL are valid.
+	_ = config.SetHTTPSettings(httpclient.Settings{Transport: transport})
+	_ = config.InitializeByValue(baseURL, form3test.AccountPath)
+
+	return &Service{
+		Account:   account.New(config),
+		transport: transport,
+	}
+}
+
+// Accounts returns the accounts stored, in creation order.
+func (s *Service) Accounts() []model.Data {
+	return s.transport.Accounts()
+}
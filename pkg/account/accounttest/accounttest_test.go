@@ -0,0 +1,191 @@
+package accounttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AdanJSuarez/form3/pkg/account"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/form3"
+	"github.com/AdanJSuarez/form3/pkg/form3test"
+	"github.com/AdanJSuarez/form3/pkg/model"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	accountIDTest      = "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"
+	otherAccountIDTest = "0d209d7f-d07a-4542-947f-5885fddddae2"
+)
+
+var dataTest = model.Data{
+	ID:             accountIDTest,
+	OrganizationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+	Type:           "accounts",
+	Attributes: model.Attributes{
+		Country:      "GB",
+		BaseCurrency: "GBP",
+		BankID:       "400300",
+		BankIDCode:   "GBDSC",
+		Bic:          "NWBKGB22",
+		Name:         []string{"Jane Doe"},
+	},
+}
+
+type TSService struct {
+	suite.Suite
+	service *Service
+}
+
+func TestRunTSService(t *testing.T) {
+	suite.Run(t, new(TSService))
+}
+
+func (ts *TSService) BeforeTest(_, _ string) {
+	ts.service = NewService()
+}
+
+// outcome keeps what the code using an account.AccountService can see of the
+// result of an operation.
+func outcome(value interface{}, err error) []interface{} {
+	if err == nil {
+		return []interface{}{value}
+	}
+	sentinels := []bool{}
+	for _, sentinel := range []error{apierror.ErrBadRequest, apierror.ErrNotFound, apierror.ErrConflict,
+		account.ErrVersionConflict} {
+		sentinels = append(sentinels, errors.Is(err, sentinel))
+	}
+	result := []interface{}{value, err.Error(), sentinels}
+	apiError := &apierror.APIError{}
+	if errors.As(err, &apiError) {
+		result = append(result, apiError.StatusCode, apiError.ErrorMessage, apiError.FieldErrors)
+	}
+	return result
+}
+
+// scenario makes the same calls to the service, to compare the Service with
+// account.Account against the fake API.
+func scenario(service account.AccountService) [][]interface{} {
+	ctx := context.Background()
+	other := dataTest
+	other.ID = otherAccountIDTest
+	other.Attributes.Country = "FR"
+	invalid := dataTest
+	invalid.Attributes.Country = "gb"
+	invalid.Attributes.Name = nil
+
+	outcomes := [][]interface{}{}
+	add := func(value interface{}, err error) {
+		outcomes = append(outcomes, outcome(value, err))
+	}
+	add(service.Create(model.DataModel{Data: dataTest}))
+	add(service.CreateWithContext(ctx, model.DataModel{Data: other}))
+	add(service.Create(model.DataModel{Data: dataTest}))
+	add(service.Create(model.DataModel{Data: invalid}))
+	add(service.Fetch(accountIDTest))
+	add(service.FetchWithContext(ctx, "00000000-0000-0000-0000-000000000000"))
+	add(service.Fetch("invalid"))
+	add(service.Update(accountIDTest, 0, model.Attributes{Status: "closed"}))
+	add(service.UpdateWithContext(ctx, accountIDTest, 0, model.Attributes{Status: "pending"}))
+	add(service.Update(accountIDTest, 1, model.Attributes{Country: "GBR"}))
+	add(service.Mutate(ctx, otherAccountIDTest, func(data *model.Data) error {
+		data.Attributes.Bic = "NWBKGB42"
+		return nil
+	}))
+	add(service.Mutate(ctx, otherAccountIDTest, func(*model.Data) error {
+		return fmt.Errorf("fakeMutationError")
+	}))
+
+	for _, iterator := range []*account.Iterator{
+		service.List(1),
+		service.ListWithContext(ctx, 0),
+		service.ListByFilter(account.Filter{Country: "FR"}, 1),
+		service.ListByFilterWithContext(ctx, account.Filter{Country: "ES"}, 1),
+	} {
+		listed := []model.Data{}
+		for iterator.Next() {
+			listed = append(listed, iterator.Value())
+		}
+		add(listed, iterator.Err())
+	}
+
+	add(nil, service.Delete(accountIDTest, 0))
+	add(nil, service.DeleteWithContext(ctx, accountIDTest, 1))
+	add(nil, service.Delete(accountIDTest, 1))
+	add(nil, service.Delete("invalid", 0))
+	return outcomes
+}
+
+func (ts *TSService) TestBehavesAsAccountAgainstFakeAPI() {
+	server := form3test.NewServer()
+	defer server.Close()
+	f3, err := form3.New()
+	ts.Require().NoError(err)
+	ts.Require().NoError(f3.ConfigurationByValue(server.URL, form3test.AccountPath))
+
+	expected := scenario(f3.Account())
+	ts.Equal(expected, scenario(ts.service))
+	ts.Equal(server.Accounts(), ts.service.Accounts())
+}
+
+func (ts *TSService) TestUpdateWithOldVersionWrapsVersionConflict() {
+	_, err := ts.service.Create(model.DataModel{Data: dataTest})
+	ts.Require().NoError(err)
+	_, err = ts.service.Update(accountIDTest, 0, model.Attributes{Status: "closed"})
+	ts.Require().NoError(err)
+
+	_, err = ts.service.Update(accountIDTest, 0, model.Attributes{Status: "pending"})
+	ts.ErrorIs(err, account.ErrVersionConflict)
+	ts.ErrorContains(err, "status code 409")
+}
+
+func (ts *TSService) TestCreateInvalidReturnsFieldErrors() {
+	invalid := dataTest
+	invalid.Attributes.Country = ""
+	_, err := ts.service.Create(model.DataModel{Data: invalid})
+	apiError := &apierror.APIError{}
+	ts.Require().ErrorAs(err, &apiError)
+	ts.Equal([]apierror.FieldError{{Field: "country", Rule: apierror.RuleRequired,
+		Message: "data.attributes.country in body is required"}}, apiError.FieldErrors)
+	ts.Empty(ts.service.Accounts())
+}
+
+func (ts *TSService) TestMutateGivesUpAfterRetries() {
+	_, err := ts.service.Create(model.DataModel{Data: dataTest})
+	ts.Require().NoError(err)
+
+	calls := 0
+	_, err = ts.service.Mutate(context.Background(), accountIDTest, func(data *model.Data) error {
+		calls++
+		// Another client updates the account in between.
+		_, err := ts.service.Update(accountIDTest, data.Version, model.Attributes{Status: "pending"})
+		ts.NoError(err)
+		data.Attributes.Status = "closed"
+		return nil
+	})
+	ts.ErrorIs(err, account.ErrVersionConflict)
+	ts.ErrorContains(err, "failed mutating account (attempts: 4)")
+	ts.Equal(4, calls)
+}
+
+func (ts *TSService) TestDoneContextReturnsContextError() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ts.service.CreateWithContext(ctx, model.DataModel{Data: dataTest})
+	ts.ErrorIs(err, context.Canceled)
+	_, err = ts.service.FetchWithContext(ctx, accountIDTest)
+	ts.ErrorIs(err, context.Canceled)
+	_, err = ts.service.UpdateWithContext(ctx, accountIDTest, 0, model.Attributes{})
+	ts.ErrorIs(err, context.Canceled)
+	_, err = ts.service.Mutate(ctx, accountIDTest, func(*model.Data) error { return nil })
+	ts.ErrorIs(err, context.Canceled)
+	ts.ErrorIs(ts.service.DeleteWithContext(ctx, accountIDTest, 0), context.Canceled)
+
+	iterator := ts.service.ListWithContext(ctx, 10)
+	ts.False(iterator.Next())
+	ts.ErrorIs(iterator.Err(), context.Canceled)
+	ts.Empty(ts.service.Accounts())
+}
@@ -0,0 +1,124 @@
+package account
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/AdanJSuarez/form3/internal/client/httpclient"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/model"
+	"github.com/AdanJSuarez/form3/pkg/retry"
+	"github.com/stretchr/testify/suite"
+)
+
+const concurrentCallsTest = 40
+
+// TSConcurrency shares one Account between goroutines against a server that
+// checks the digest of every body and echoes the accounts created. Run it with
+// -race to detect shared state.
+type TSConcurrency struct {
+	suite.Suite
+	server  *httptest.Server
+	account *Account
+}
+
+func TestRunTSConcurrency(t *testing.T) {
+	suite.Run(t, new(TSConcurrency))
+}
+
+func (ts *TSConcurrency) BeforeTest(_, _ string) {
+	ts.server = httptest.NewServer(http.HandlerFunc(ts.serveAccounts))
+	serverURL, err := url.Parse(ts.server.URL)
+	ts.Require().NoError(err)
+
+	configuration := newMockAccountConfig(ts.T())
+	configuration.On("BaseURL").Return(serverURL)
+	configuration.On("AccountPath").Return(accountPath)
+	configuration.On("RetryPolicy").Return(retry.NoRetries())
+	configuration.On("StatusHandlers").Return(map[int]apierror.StatusHandler{})
+	configuration.On("CompressionThreshold").Return(256)
+	configuration.On("MutateRetries").Return(3)
+	configuration.On("Authenticator").Return(nil)
+	configuration.On("HTTPSettings").Return(httpclient.Settings{})
+	ts.account = New(configuration)
+}
+
+func (ts *TSConcurrency) AfterTest(_, _ string) {
+	ts.server.Close()
+}
+
+func (ts *TSConcurrency) serveAccounts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		hash := sha256.Sum256(body)
+		if r.Header.Get("Digest") != "sha-256="+base64.StdEncoding.EncodeToString(hash[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if body, err = io.ReadAll(reader); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(model.DataModel{Data: model.Data{ID: path.Base(r.URL.Path)}})
+	case http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (ts *TSConcurrency) dataModel(i int) model.DataModel {
+	data := dataModelRequest
+	data.Data.ID = fmt.Sprintf("account-%d", i)
+	// Bodies of different sizes, some over the compression threshold.
+	data.Data.Attributes.Name = []string{fmt.Sprintf("%0*d", i*10, i)}
+	return data
+}
+
+func (ts *TSConcurrency) TestSharedAccountKeepsEveryCallApart() {
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentCallsTest; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			expected := ts.dataModel(i)
+
+			created, err := ts.account.Create(expected)
+			ts.NoError(err)
+			ts.Equal(expected, created)
+
+			fetched, err := ts.account.Fetch(expected.Data.ID)
+			ts.NoError(err)
+			ts.Equal(expected.Data.ID, fetched.Data.ID)
+
+			ts.NoError(ts.account.Delete(expected.Data.ID, 0))
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,37 @@
+package account
+
+import "github.com/AdanJSuarez/form3/internal/client/request"
+
+const (
+	bankIDFilter        = "bank_id"
+	bankIDCodeFilter    = "bank_id_code"
+	accountNumberFilter = "account_number"
+	ibanFilter          = "iban"
+	countryFilter       = "country"
+	customerIDFilter    = "customer_id"
+)
+
+/*
+Filter holds the values used to filter the accounts when listing them. Only
+the accounts matching every non empty field are returned.
+
+For more reference about the filter values, please check form3 API documentation.
+*/
+type Filter struct {
+	BankID        string
+	BankIDCode    string
+	AccountNumber string
+	Iban          string
+	Country       string
+	CustomerID    string
+}
+
+func (f Filter) addTo(query *request.Query) *request.Query {
+	return query.
+		AddFilter(bankIDFilter, f.BankID).
+		AddFilter(bankIDCodeFilter, f.BankIDCode).
+		AddFilter(accountNumberFilter, f.AccountNumber).
+		AddFilter(ibanFilter, f.Iban).
+		AddFilter(countryFilter, f.Country).
+		AddFilter(customerIDFilter, f.CustomerID)
+}
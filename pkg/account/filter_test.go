@@ -0,0 +1,37 @@
+package account
+
+import (
+	"testing"
+
+	"github.com/AdanJSuarez/form3/internal/client/request"
+	"github.com/stretchr/testify/suite"
+)
+
+type TSFilter struct{ suite.Suite }
+
+func TestRunTSFilter(t *testing.T) {
+	suite.Run(t, new(TSFilter))
+}
+
+func (ts *TSFilter) TestEmptyFilterAddsNothing() {
+	query := Filter{}.addTo(request.NewQuery())
+	ts.Empty(query.Encode())
+}
+
+func (ts *TSFilter) TestFilterAddsEveryField() {
+	filter := Filter{
+		BankID:        "400300",
+		BankIDCode:    "GBDSC",
+		AccountNumber: "41426819",
+		Iban:          "GB11NWBK40030041426819",
+		Country:       "GB",
+		CustomerID:    "fakeCustomer",
+	}
+	query := filter.addTo(request.NewQuery())
+	ts.Equal("400300", query.Get("filter[bank_id]"))
+	ts.Equal("GBDSC", query.Get("filter[bank_id_code]"))
+	ts.Equal("41426819", query.Get("filter[account_number]"))
+	ts.Equal("GB11NWBK40030041426819", query.Get("filter[iban]"))
+	ts.Equal("GB", query.Get("filter[country]"))
+	ts.Equal("fakeCustomer", query.Get("filter[customer_id]"))
+}
@@ -1,19 +1,37 @@
 package account
 
 import (
+	"context"
 	"net/http"
 	"net/url"
+
+	"github.com/AdanJSuarez/form3/internal/client/httpclient"
+	"github.com/AdanJSuarez/form3/internal/client/request"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/retry"
 )
 
-//go:generate mockery --inpackage --name=Client
-//go:generate mockery --inpackage --name=Configuration
-type Client interface {
-	Get(accountID string) (*http.Response, error)
-	Post(data interface{}) (*http.Response, error)
-	Delete(accountID, parameterKey, parameterValue string) (*http.Response, error)
+//go:generate mockery --inpackage --name=apiClient
+//go:generate mockery --inpackage --name=accountConfig
+
+// apiClient sends the requests of Account to the API. It is internal, like the
+// types it uses.
+type apiClient interface {
+	Get(ctx context.Context, accountID string) (*http.Response, error)
+	List(ctx context.Context, query *request.Query) (*http.Response, error)
+	Post(ctx context.Context, data interface{}) (*http.Response, error)
+	Patch(ctx context.Context, accountID string, data interface{}) (*http.Response, error)
+	Delete(ctx context.Context, accountID string, query *request.Query) (*http.Response, error)
 }
 
-type Configuration interface {
+// accountConfig is the configuration Account is built from.
+type accountConfig interface {
 	BaseURL() *url.URL
 	AccountPath() string
+	RetryPolicy() retry.Policy
+	StatusHandlers() map[int]apierror.StatusHandler
+	CompressionThreshold() int
+	MutateRetries() int
+	Authenticator() request.Authenticator
+	HTTPSettings() httpclient.Settings
 }
@@ -0,0 +1,216 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/AdanJSuarez/form3/internal/client/drain"
+	"github.com/AdanJSuarez/form3/internal/client/request"
+	"github.com/AdanJSuarez/form3/pkg/model"
+)
+
+const (
+	firstPageNumber  = 0
+	defaultPageSize  = 100
+	nextLinkErrorFmt = "failed parsing next link: %v"
+)
+
+/*
+Iterator walks through the accounts returned by a list request. Pages are
+fetched on demand, only when the accounts already retrieved have been consumed,
+following the "links.next" value returned by the API until there is none, or
+the page fetched is the one of "links.last".
+
+Example:
+
+	iterator := account.List(50)
+	for iterator.Next() {
+		data := iterator.Value()
+	}
+	if err := iterator.Err(); err != nil {
+		...
+	}
+*/
+type Iterator struct {
+	ctx     context.Context
+	client  apiClient
+	query   *request.Query
+	fetcher PageFetcher
+	number  int
+	size    int
+	page    []model.Data
+	index   int
+	current model.Data
+	done    bool
+	err     error
+}
+
+// PageFetcher returns the accounts of the page with the number (from 0) and size
+// given, and whether it is the last page.
+type PageFetcher func(ctx context.Context, pageNumber, pageSize int) (page []model.Data, last bool, err error)
+
+func newIterator(ctx context.Context, client apiClient, filter Filter, pageSize int) *Iterator {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	query := request.NewQuery().SetPage(firstPageNumber, pageSize)
+
+	return &Iterator{
+		ctx:    ctx,
+		client: client,
+		query:  filter.addTo(query),
+	}
+}
+
+/*
+NewIterator returns an Iterator over the pages returned by fetch, from the first
+one, of pageSize accounts (100 if zero or negative). It lets the implementations
+of AccountService that don't use the API, like the test doubles of the code using
+the library, return Iterators.
+*/
+func NewIterator(ctx context.Context, pageSize int, fetch PageFetcher) *Iterator {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	return &Iterator{
+		ctx:     ctx,
+		fetcher: fetch,
+		number:  firstPageNumber,
+		size:    pageSize,
+	}
+}
+
+/*
+Next advances the iterator to the next account, fetching a new page from the
+API when needed. It returns false when there are no more accounts or when an
+error happened. Check Err to tell them apart.
+*/
+func (i *Iterator) Next() bool {
+	for i.index >= len(i.page) {
+		if i.done || i.err != nil {
+			return false
+		}
+		if err := i.fetchPage(); err != nil {
+			i.err = err
+		}
+	}
+
+	i.current = i.page[i.index]
+	i.index++
+	return true
+}
+
+// Value returns the account the iterator is currently pointing to.
+func (i *Iterator) Value() model.Data {
+	return i.current
+}
+
+// Err returns the first error found while fetching pages, if any.
+func (i *Iterator) Err() error {
+	return i.err
+}
+
+func (i *Iterator) fetchPage() error {
+	if i.fetcher != nil {
+		return i.fetchPageFromFetcher()
+	}
+
+	response, err := i.client.List(i.ctx, i.query)
+	if err != nil {
+		return err
+	}
+
+	defer i.closeBody(response)
+
+	listDataModel, err := i.decodeResponse(response)
+	if err != nil {
+		return err
+	}
+
+	i.page = listDataModel.Data
+	i.index = 0
+
+	// The accounts of this page are still served even if the next link is wrong.
+	return i.setNextQuery(listDataModel.Links)
+}
+
+func (i *Iterator) fetchPageFromFetcher() error {
+	page, last, err := i.fetcher(i.ctx, i.number, i.size)
+	if err != nil {
+		return err
+	}
+
+	i.page = page
+	i.index = 0
+	i.number++
+	i.done = last || len(page) == 0
+	return nil
+}
+
+func (i *Iterator) setNextQuery(links model.Links) error {
+	if links.Next == "" || len(i.page) == 0 || i.isLastPage(links.Last) {
+		i.done = true
+		return nil
+	}
+
+	nextPageNumber, err := linkPageNumber(links.Next)
+	if err != nil {
+		i.done = true
+		return fmt.Errorf(nextLinkErrorFmt, err)
+	}
+	if nextPageNumber == "" {
+		i.done = true
+		return nil
+	}
+
+	// Only the page number is taken from the link so the filters are kept.
+	i.query.SetPageNumber(nextPageNumber)
+	return nil
+}
+
+// isLastPage reports whether the "links.last" value points to the page just
+// fetched, so there is no need to fetch the next one. A last link that cannot be
+// parsed is ignored.
+func (i *Iterator) isLastPage(lastLink string) bool {
+	if lastLink == "" {
+		return false
+	}
+	lastPageNumber, err := linkPageNumber(lastLink)
+	return err == nil && lastPageNumber != "" && lastPageNumber == i.query.PageNumber()
+}
+
+// linkPageNumber returns the page number of the link, or empty if there is none.
+func linkPageNumber(link string) (string, error) {
+	linkURL, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+
+	linkQuery, err := request.ParseQuery(linkURL.RawQuery)
+	if err != nil {
+		return "", err
+	}
+	return linkQuery.PageNumber(), nil
+}
+
+func (i *Iterator) decodeResponse(response *http.Response) (model.ListDataModel, error) {
+	listDataModel := model.ListDataModel{}
+	if response == nil {
+		return listDataModel, fmt.Errorf(httpResponseNilError)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&listDataModel); err != nil {
+		return listDataModel, err
+	}
+
+	return listDataModel, nil
+}
+
+func (i *Iterator) closeBody(response *http.Response) {
+	drain.Close(response)
+}
@@ -0,0 +1,227 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/AdanJSuarez/form3/pkg/model"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	uuidTest2 = "223e4567-e89b-12d3-a456-426614174000"
+	uuidTest3 = "323e4567-e89b-12d3-a456-426614174000"
+	nextLink  = "/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=2"
+)
+
+var (
+	iteratorTest   *Iterator
+	listClientMock *mockApiClient
+	firstPageTest  = model.ListDataModel{
+		Data: []model.Data{
+			{ID: uuidTest, OrganizationID: organizationID},
+			{ID: uuidTest2, OrganizationID: organizationID},
+		},
+		Links: model.Links{Next: nextLink},
+	}
+	lastPageTest = model.ListDataModel{
+		Data: []model.Data{
+			{ID: uuidTest3, OrganizationID: organizationID},
+		},
+	}
+)
+
+type TSIterator struct{ suite.Suite }
+
+func TestRunTSIterator(t *testing.T) {
+	suite.Run(t, new(TSIterator))
+}
+
+func (ts *TSIterator) BeforeTest(_, _ string) {
+	listClientMock = newMockApiClient(ts.T())
+	iteratorTest = newIterator(context.Background(), listClientMock, Filter{}, 2)
+	ts.IsType(new(Iterator), iteratorTest)
+}
+
+func (ts *TSIterator) TestNextIteratesThroughAllPages() {
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(firstPageTest), nil).Once()
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(lastPageTest), nil).Once()
+
+	ids := []string{}
+	for iteratorTest.Next() {
+		ids = append(ids, iteratorTest.Value().ID)
+	}
+
+	ts.NoError(iteratorTest.Err())
+	ts.Equal([]string{uuidTest, uuidTest2, uuidTest3}, ids)
+	ts.Equal("1", iteratorTest.query.PageNumber())
+}
+
+func (ts *TSIterator) TestNextKeepsFilterOnNextPages() {
+	iterator := newIterator(context.Background(), listClientMock, Filter{Country: "GB"}, 2)
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(firstPageTest), nil).Once()
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(lastPageTest), nil).Once()
+
+	for iterator.Next() {
+	}
+
+	ts.NoError(iterator.Err())
+	ts.Equal("1", iterator.query.PageNumber())
+	ts.Equal("GB", iterator.query.Get("filter[country]"))
+}
+
+func (ts *TSIterator) TestNextFetchesPagesLazily() {
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(firstPageTest), nil).Once()
+
+	ts.True(iteratorTest.Next())
+	ts.True(iteratorTest.Next())
+	listClientMock.AssertNumberOfCalls(ts.T(), "List", 1)
+}
+
+func (ts *TSIterator) TestNextWithEmptyPageReturnsFalse() {
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(model.ListDataModel{}), nil).Once()
+
+	ts.False(iteratorTest.Next())
+	ts.False(iteratorTest.Next())
+	ts.NoError(iteratorTest.Err())
+}
+
+func (ts *TSIterator) TestNextWithErrorOnListReturnsFalseAndError() {
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(nil, fmt.Errorf(statusNotFoundMsg)).Once()
+
+	ts.False(iteratorTest.Next())
+	ts.ErrorContains(iteratorTest.Err(), "status code 404:")
+	ts.False(iteratorTest.Next())
+}
+
+func (ts *TSIterator) TestNextWithDecodeErrorReturnsFalseAndError() {
+	res := &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(bytes.NewBuffer([]byte("fakeReturnedBodyError"))),
+	}
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(res, nil).Once()
+
+	ts.False(iteratorTest.Next())
+	ts.ErrorContains(iteratorTest.Err(), "invalid character")
+}
+
+func (ts *TSIterator) TestNextWithInvalidNextLinkReturnsError() {
+	page := firstPageTest
+	page.Links = model.Links{Next: "%zz"}
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(page), nil).Once()
+
+	ts.True(iteratorTest.Next())
+	ts.True(iteratorTest.Next())
+	ts.False(iteratorTest.Next())
+	ts.ErrorContains(iteratorTest.Err(), "failed parsing next link")
+}
+
+func (ts *TSIterator) TestNextLinkWithoutPageNumberStopsIterating() {
+	page := firstPageTest
+	page.Links = model.Links{Next: "/v1/organisation/accounts"}
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(page), nil).Once()
+
+	ts.True(iteratorTest.Next())
+	ts.True(iteratorTest.Next())
+	ts.False(iteratorTest.Next())
+	ts.NoError(iteratorTest.Err())
+}
+
+func (ts *TSIterator) TestLastLinkOfCurrentPageStopsIterating() {
+	page := firstPageTest
+	page.Links = model.Links{
+		Next: nextLink,
+		Last: "/v1/organisation/accounts?page%5Bnumber%5D=0&page%5Bsize%5D=2",
+	}
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(page), nil).Once()
+
+	ts.True(iteratorTest.Next())
+	ts.True(iteratorTest.Next())
+	ts.False(iteratorTest.Next())
+	ts.NoError(iteratorTest.Err())
+}
+
+func (ts *TSIterator) TestLastLinkOfLaterPageKeepsIterating() {
+	page := firstPageTest
+	page.Links = model.Links{Next: nextLink, Last: nextLink}
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(page), nil).Once()
+	listClientMock.On("List", mock.Anything, mock.Anything).Return(listResponse(lastPageTest), nil).Once()
+
+	ids := []string{}
+	for iteratorTest.Next() {
+		ids = append(ids, iteratorTest.Value().ID)
+	}
+	ts.NoError(iteratorTest.Err())
+	ts.Equal([]string{uuidTest, uuidTest2, uuidTest3}, ids)
+}
+
+func (ts *TSIterator) TestNewIteratorWithInvalidPageSizeUsesDefault() {
+	iterator := newIterator(context.Background(), listClientMock, Filter{}, 0)
+	ts.Equal(fmt.Sprint(defaultPageSize), iterator.query.PageSize())
+}
+
+func (ts *TSIterator) TestNewIteratorIteratesThroughFetchedPages() {
+	pages := [][]model.Data{firstPageTest.Data, lastPageTest.Data}
+	requested := []int{}
+	iterator := NewIterator(context.Background(), 2, func(_ context.Context, number, size int) ([]model.Data, bool, error) {
+		ts.Equal(2, size)
+		requested = append(requested, number)
+		return pages[number], number == len(pages)-1, nil
+	})
+
+	ids := []string{}
+	for iterator.Next() {
+		ids = append(ids, iterator.Value().ID)
+	}
+	ts.NoError(iterator.Err())
+	ts.Equal([]string{uuidTest, uuidTest2, uuidTest3}, ids)
+	ts.Equal([]int{0, 1}, requested)
+}
+
+func (ts *TSIterator) TestNewIteratorStopsOnEmptyPage() {
+	calls := 0
+	iterator := NewIterator(context.Background(), 0, func(_ context.Context, _, size int) ([]model.Data, bool, error) {
+		ts.Equal(defaultPageSize, size)
+		calls++
+		return nil, false, nil
+	})
+
+	ts.False(iterator.Next())
+	ts.False(iterator.Next())
+	ts.NoError(iterator.Err())
+	ts.Equal(1, calls)
+}
+
+func (ts *TSIterator) TestNewIteratorReturnsFetchError() {
+	iterator := NewIterator(context.Background(), 2, func(_ context.Context, number, _ int) ([]model.Data, bool, error) {
+		if number == 0 {
+			return firstPageTest.Data, false, nil
+		}
+		return nil, false, fmt.Errorf("fakeFetchError")
+	})
+
+	ts.True(iterator.Next())
+	ts.True(iterator.Next())
+	ts.False(iterator.Next())
+	ts.EqualError(iterator.Err(), "fakeFetchError")
+}
+
+func (ts *TSIterator) TestNilResponseReturnsError() {
+	data, err := iteratorTest.decodeResponse(nil)
+	ts.ErrorContains(err, "http response is nil")
+	ts.Empty(data)
+}
+
+func listResponse(listDataModel model.ListDataModel) *http.Response {
+	body, _ := json.Marshal(listDataModel)
+	return &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(bytes.NewBuffer(body)),
+	}
+}
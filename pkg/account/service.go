@@ -0,0 +1,37 @@
+package account
+
+import (
+	"context"
+
+	"github.com/AdanJSuarez/form3/pkg/model"
+)
+
+/*
+AccountService has the operations of Account. Depend on it, instead of on
+*Account, to replace Account in the tests of the code using the library, for
+example with the in-memory accounttest.Service:
+
+	type Payments struct {
+		accounts account.AccountService
+	}
+
+	payments := Payments{accounts: f3.Account()}
+*/
+type AccountService interface {
+	Create(data model.DataModel) (model.DataModel, error)
+	CreateWithContext(ctx context.Context, data model.DataModel) (model.DataModel, error)
+	Fetch(accountID string) (model.DataModel, error)
+	FetchWithContext(ctx context.Context, accountID string) (model.DataModel, error)
+	List(pageSize int) *Iterator
+	ListWithContext(ctx context.Context, pageSize int) *Iterator
+	ListByFilter(filter Filter, pageSize int) *Iterator
+	ListByFilterWithContext(ctx context.Context, filter Filter, pageSize int) *Iterator
+	Update(accountID string, version int64, changes model.Attributes) (model.DataModel, error)
+	UpdateWithContext(ctx context.Context, accountID string, version int64,
+		changes model.Attributes) (model.DataModel, error)
+	Mutate(ctx context.Context, accountID string, mutation func(*model.Data) error) (model.DataModel, error)
+	Delete(accountID string, version int) error
+	DeleteWithContext(ctx context.Context, accountID string, version int) error
+}
+
+var _ AccountService = (*Account)(nil)
@@ -0,0 +1,122 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	RequestIDHeader = "X-Request-Id"
+	errorFmt        = "status code %d: %s"
+)
+
+// Sentinel errors matched, using errors.Is, by the APIError with the related status code.
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrMethodNotAllowed   = errors.New("method not allowed")
+	ErrNotAcceptable      = errors.New("not acceptable")
+	ErrConflict           = errors.New("conflict")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrServerError        = errors.New("internal server error")
+	ErrBadGateway         = errors.New("bad gateway")
+	ErrServiceUnavailable = errors.New("service unavailable")
+	ErrGatewayTimeout     = errors.New("gateway timeout")
+)
+
+var sentinelByStatusCode = map[int]error{
+	http.StatusBadRequest:          ErrBadRequest,
+	http.StatusUnauthorized:        ErrUnauthorized,
+	http.StatusForbidden:           ErrForbidden,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusMethodNotAllowed:    ErrMethodNotAllowed,
+	http.StatusNotAcceptable:       ErrNotAcceptable,
+	http.StatusConflict:            ErrConflict,
+	http.StatusTooManyRequests:     ErrRateLimited,
+	http.StatusInternalServerError: ErrServerError,
+	http.StatusBadGateway:          ErrBadGateway,
+	http.StatusServiceUnavailable:  ErrServiceUnavailable,
+	http.StatusGatewayTimeout:      ErrGatewayTimeout,
+}
+
+/*
+APIError is the error returned when the Form3 API answers with an error status
+code. Depending on the status code, the API body has an error_code and an
+error_message, or an error and an error_description. The fields are empty if
+the body has none of them.
+
+Use errors.As to get it from the error returned by the account methods, or
+errors.Is with the sentinel errors of this package to check the kind of error:
+
+	if errors.Is(err, apierror.ErrNotFound) { ... }
+*/
+type APIError struct {
+	StatusCode int
+	// ErrorCode and ErrorMessage are the error_code and error_message of the body.
+	ErrorCode    string
+	ErrorMessage string
+	// ErrorType and ErrorDescription are the error and error_description of the body.
+	ErrorType        string
+	ErrorDescription string
+	// RequestID is the X-Request-Id header of the response, useful to ask Form3 support.
+	RequestID string
+	// FieldErrors are the failures of the validation failure list of a 400 Bad Request.
+	FieldErrors []FieldError
+	// Body is the raw body of the response.
+	Body []byte
+	// Message is the human readable description of the error.
+	Message string
+}
+
+func (a *APIError) Error() string {
+	message := a.Message
+	if message == "" {
+		message = http.StatusText(a.StatusCode)
+	}
+	return fmt.Sprintf(errorFmt, a.StatusCode, message)
+}
+
+// Is reports whether target is the sentinel error of the status code.
+func (a *APIError) Is(target error) bool {
+	sentinel, ok := sentinelByStatusCode[a.StatusCode]
+	return ok && sentinel == target
+}
+
+// Rules of a FieldError.
+const (
+	RuleRequired  = "required"
+	RulePattern   = "pattern"
+	RuleEnum      = "enum"
+	RuleType      = "type"
+	RuleMaxLength = "maxLength"
+	RuleMinLength = "minLength"
+	RuleMaximum   = "maximum"
+	RuleMinimum   = "minimum"
+	RuleMaxItems  = "maxItems"
+	RuleMinItems  = "minItems"
+	RuleUnknown   = "unknown"
+)
+
+/*
+FieldError is a validation failure of a field of the request body. Field is the
+json name of the field, relative to the attributes (like "bank_id" or "name.0")
+or to the data if it is not an attribute (like "id"). Rule is the kind of
+validation failed (RuleRequired, RulePattern, ...) and Message the failure as
+returned by the API.
+*/
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+/*
+StatusHandler returns the error for a response with an error status code. It
+overrides the error returned by default for the status codes it is registered
+for. If it returns nil, the default error is returned, so it should not read the
+body in that case.
+*/
+type StatusHandler func(response *http.Response) error
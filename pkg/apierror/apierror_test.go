@@ -0,0 +1,46 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TSAPIError struct{ suite.Suite }
+
+func TestRunTSAPIError(t *testing.T) {
+	suite.Run(t, new(TSAPIError))
+}
+
+func (ts *TSAPIError) TestErrorKeepsStatusCodeFormat() {
+	err := &APIError{StatusCode: http.StatusNotFound, Message: "fake not found"}
+	ts.EqualError(err, "status code 404: fake not found")
+}
+
+func (ts *TSAPIError) TestErrorWithoutMessageUsesStatusText() {
+	err := &APIError{StatusCode: http.StatusBadGateway}
+	ts.EqualError(err, "status code 502: Bad Gateway")
+}
+
+func (ts *TSAPIError) TestIsMatchesSentinelOfStatusCode() {
+	for statusCode, sentinel := range sentinelByStatusCode {
+		err := fmt.Errorf("wrapped: %w", &APIError{StatusCode: statusCode})
+		ts.True(errors.Is(err, sentinel), statusCode)
+	}
+}
+
+func (ts *TSAPIError) TestIsNotMatchesOtherSentinels() {
+	err := &APIError{StatusCode: http.StatusConflict}
+	ts.False(errors.Is(err, ErrNotFound))
+	ts.False(errors.Is(&APIError{StatusCode: 777}, ErrConflict))
+}
+
+func (ts *TSAPIError) TestAsReturnsAPIError() {
+	err := fmt.Errorf("wrapped: %w", &APIError{StatusCode: http.StatusBadRequest, ErrorCode: "fakeCode"})
+	apiError := &APIError{}
+	ts.True(errors.As(err, &apiError))
+	ts.Equal("fakeCode", apiError.ErrorCode)
+}
@@ -0,0 +1,88 @@
+/*
+Package cassette has a http.RoundTripper that records the requests sent to the
+Form3 API, and their responses, into a cassette file, and replays them in tests,
+without the API.
+
+Record the interactions once against the API:
+
+	recorder, _ := cassette.New("testdata/accounts.json", cassette.Options{Mode: cassette.ModeRecord})
+	defer recorder.Save()
+
+	f3, _ := form3.New(form3.WithTransport(recorder))
+
+And replay them in the tests, with the same calls, changing only the mode:
+
+	recorder, _ := cassette.New("testdata/accounts.json", cassette.Options{Mode: cassette.ModeReplay})
+*/
+package cassette
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const (
+	readCassetteErrorFmt   = "failed reading cassette %s: %v"
+	decodeCassetteErrorFmt = "failed decoding cassette %s: %v"
+	writeCassetteErrorFmt  = "failed writing cassette %s: %v"
+)
+
+// Cassette is the content of a cassette file: the interactions in the order
+// they were recorded.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response received for it.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a request as recorded, with the headers and body fields redacted.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a response as recorded, with the headers and body fields redacted.
+// The body is recorded decompressed.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Load returns the cassette of the file in path.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(readCassetteErrorFmt, path, err)
+	}
+
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf(decodeCassetteErrorFmt, path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette in the file in path, creating its folder if needed.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf(writeCassetteErrorFmt, path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf(writeCassetteErrorFmt, path, err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf(writeCassetteErrorFmt, path, err)
+	}
+	return nil
+}
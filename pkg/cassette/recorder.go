@@ -0,0 +1,303 @@
+package cassette
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Mode tells whether a Recorder replays the interactions of its cassette or
+// records new ones.
+type Mode int
+
+const (
+	// ModeReplay answers the requests with the interactions of the cassette,
+	// without sending them.
+	ModeReplay Mode = iota
+	// ModeRecord sends the requests and records the interactions, to write them
+	// in the cassette with Save.
+	ModeRecord
+)
+
+// Redacted is the value recorded instead of the redacted headers and body fields.
+const Redacted = "REDACTED"
+
+const (
+	authorizationHeader   = "Authorization"
+	contentTypeHeader     = "Content-Type"
+	contentEncodingHeader = "Content-Encoding"
+	contentLengthHeader   = "Content-Length"
+	gzipEncoding          = "gzip"
+	statusFmt             = "%d %s"
+
+	invalidModeErrorFmt   = "invalid cassette mode %d"
+	noInteractionErrorFmt = "no interaction left in cassette %s for %s %s"
+	readBodyErrorFmt      = "failed reading body to record: %v"
+)
+
+// Options configures a Recorder.
+type Options struct {
+	// Mode is ModeReplay by default.
+	Mode Mode
+	// Transport sends the requests in ModeRecord. It is http.DefaultTransport by default.
+	Transport http.RoundTripper
+	// RedactHeaders are the headers of the requests and responses recorded with the
+	// value Redacted. The Authorization header is always redacted.
+	RedactHeaders []string
+	// RedactBodyFields are the fields of the JSON and form bodies recorded with the
+	// value Redacted, at any depth, like "client_secret" or "access_token".
+	RedactBodyFields []string
+}
+
+/*
+Recorder is a http.RoundTripper that records the interactions into a cassette
+file, or replays them, as its Mode says. A request is answered with the first
+interaction not replayed yet with the same method, path, query and body. The
+host is not matched, so the cassettes can be replayed against any base URL, and
+the JSON bodies are compared normalized, with their fields in any order. The
+bodies compressed with gzip are recorded decompressed.
+
+It is safe for concurrent use.
+*/
+type Recorder struct {
+	path             string
+	mode             Mode
+	transport        http.RoundTripper
+	redactHeaders    []string
+	redactBodyFields map[string]bool
+
+	mu       sync.Mutex
+	cassette *Cassette
+	replayed []bool
+}
+
+// New returns a Recorder of the cassette file in path. In ModeReplay the file is
+// read, and it returns an error if it cannot be.
+func New(path string, options Options) (*Recorder, error) {
+	recorder := &Recorder{
+		path:             path,
+		mode:             options.Mode,
+		transport:        options.Transport,
+		redactHeaders:    append([]string{authorizationHeader}, options.RedactHeaders...),
+		redactBodyFields: map[string]bool{},
+		cassette:         &Cassette{},
+	}
+	if recorder.transport == nil {
+		recorder.transport = http.DefaultTransport
+	}
+	for _, field := range options.RedactBodyFields {
+		recorder.redactBodyFields[field] = true
+	}
+
+	switch options.Mode {
+	case ModeRecord:
+	case ModeReplay:
+		cassette, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		recorder.cassette = cassette
+		recorder.replayed = make([]bool, len(cassette.Interactions))
+	default:
+		return nil, fmt.Errorf(invalidModeErrorFmt, options.Mode)
+	}
+	return recorder, nil
+}
+
+// RoundTrip replays or records the interaction of the request.
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := r.readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(request, body)
+	}
+	return r.record(request, body)
+}
+
+// Save writes the interactions recorded in the cassette file. In ModeReplay it
+// does nothing, so it can be deferred in both modes.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// NotReplayed returns how many interactions of the cassette have not been
+// replayed yet.
+func (r *Recorder) NotReplayed() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, replayed := range r.replayed {
+		if !replayed {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *Recorder) replay(request *http.Request, body []byte) (*http.Response, error) {
+	recorded := r.recordRequest(request, body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if !r.replayed[i] && matches(interaction.Request, recorded) {
+			r.replayed[i] = true
+			return interaction.Response.httpResponse(request), nil
+		}
+	}
+	return nil, fmt.Errorf(noInteractionErrorFmt, r.path, request.Method, request.URL.Path)
+}
+
+func (r *Recorder) record(request *http.Request, body []byte) (*http.Response, error) {
+	outgoing := request.Clone(request.Context())
+	if request.Body != nil {
+		outgoing.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	response, err := r.transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf(readBodyErrorFmt, err)
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction := Interaction{
+		Request:  r.recordRequest(request, body),
+		Response: r.recordResponse(response, responseBody),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	return response, nil
+}
+
+// readRequestBody reads and closes the body of the request, as a RoundTripper
+// must do.
+func (r *Recorder) readRequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+	defer request.Body.Close()
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, fmt.Errorf(readBodyErrorFmt, err)
+	}
+	return body, nil
+}
+
+func (r *Recorder) recordRequest(request *http.Request, body []byte) Request {
+	header := r.redactHeader(request.Header)
+	body = decompressed(header, body)
+	return Request{
+		Method: request.Method,
+		URL:    request.URL.String(),
+		Header: header,
+		Body:   string(r.redactBody(body, request.Header.Get(contentTypeHeader))),
+	}
+}
+
+func (r *Recorder) recordResponse(response *http.Response, body []byte) Response {
+	header := r.redactHeader(response.Header)
+	body = decompressed(header, body)
+	return Response{
+		StatusCode: response.StatusCode,
+		Header:     header,
+		Body:       string(r.redactBody(body, header.Get(contentTypeHeader))),
+	}
+}
+
+// redactHeader returns a copy of the header with the values of the redacted
+// headers replaced.
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	if redacted == nil {
+		return nil
+	}
+	for _, key := range r.redactHeaders {
+		if _, ok := redacted[http.CanonicalHeaderKey(key)]; ok {
+			redacted.Set(key, Redacted)
+		}
+	}
+	return redacted
+}
+
+// decompressed returns the body decompressed if the header says it is compressed
+// with gzip, and removes the headers that no longer apply to it. Other bodies are
+// returned as they are.
+func decompressed(header http.Header, body []byte) []byte {
+	if !strings.EqualFold(strings.TrimSpace(header.Get(contentEncodingHeader)), gzipEncoding) {
+		return body
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return body
+	}
+	defer reader.Close()
+	plain, err := io.ReadAll(reader)
+	if err != nil {
+		return body
+	}
+
+	header.Del(contentEncodingHeader)
+	header.Del(contentLengthHeader)
+	return plain
+}
+
+// matches reports whether the request is the recorded one: same method, path,
+// query and body. The bodies are compared redacted and normalized.
+func matches(recorded, request Request) bool {
+	if recorded.Method != request.Method || recorded.Body != request.Body {
+		return false
+	}
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	requestURL, err := url.Parse(request.URL)
+	if err != nil {
+		return false
+	}
+	return recordedURL.Path == requestURL.Path &&
+		reflect.DeepEqual(recordedURL.Query(), requestURL.Query())
+}
+
+func (r Response) httpResponse(request *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf(statusFmt, r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       request,
+	}
+}
@@ -0,0 +1,264 @@
+package cassette
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdanJSuarez/form3/pkg/form3"
+	"github.com/AdanJSuarez/form3/pkg/form3test"
+	"github.com/AdanJSuarez/form3/pkg/model"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	accountIDTest = "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"
+	deadURLTest   = "http://127.0.0.1:1"
+)
+
+var dataTest = model.Data{
+	ID:             accountIDTest,
+	OrganizationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+	Type:           "accounts",
+	Attributes: model.Attributes{
+		Country: "GB",
+		Name:    []string{"Jane Doe"},
+	},
+}
+
+type TSRecorder struct {
+	suite.Suite
+	path string
+}
+
+func TestRunTSRecorder(t *testing.T) {
+	suite.Run(t, new(TSRecorder))
+}
+
+func (ts *TSRecorder) BeforeTest(_, _ string) {
+	ts.path = filepath.Join(ts.T().TempDir(), "testdata", "cassette.json")
+}
+
+func (ts *TSRecorder) recorder(options Options) *Recorder {
+	recorder, err := New(ts.path, options)
+	ts.Require().NoError(err)
+	return recorder
+}
+
+func (ts *TSRecorder) send(recorder *Recorder, method, url, contentType, body string) *http.Response {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	ts.Require().NoError(err)
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	response, err := recorder.RoundTrip(request)
+	ts.Require().NoError(err)
+	return response
+}
+
+func (ts *TSRecorder) readAll(response *http.Response) string {
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	ts.NoError(err)
+	return string(body)
+}
+
+// accountCalls makes the same calls to the account API, to record and replay them.
+func (ts *TSRecorder) accountCalls(recorder *Recorder, baseURL string) []interface{} {
+	f3, err := form3.New(form3.WithTransport(recorder))
+	ts.Require().NoError(err)
+	ts.Require().NoError(f3.ConfigurationByValue(baseURL, form3test.AccountPath))
+	accounts := f3.Account()
+
+	results := []interface{}{}
+	created, err := accounts.Create(model.DataModel{Data: dataTest})
+	results = append(results, created, err)
+	_, err = accounts.Create(model.DataModel{Data: dataTest})
+	results = append(results, err.Error())
+	fetched, err := accounts.Fetch(accountIDTest)
+	results = append(results, fetched, err)
+	updated, err := accounts.Update(accountIDTest, 0, model.Attributes{Status: "closed"})
+	results = append(results, updated, err)
+	iterator := accounts.List(10)
+	for iterator.Next() {
+		results = append(results, iterator.Value())
+	}
+	results = append(results, iterator.Err(), accounts.Delete(accountIDTest, 1))
+	return results
+}
+
+func (ts *TSRecorder) TestRecordsAndReplaysAccountCalls() {
+	server := form3test.NewServer()
+	recorder := ts.recorder(Options{Mode: ModeRecord})
+	recorded := ts.accountCalls(recorder, server.URL)
+	server.Close()
+	ts.NoError(recorder.Save())
+
+	recorder = ts.recorder(Options{Mode: ModeReplay})
+	ts.Equal(6, recorder.NotReplayed())
+	replayed := ts.accountCalls(recorder, deadURLTest)
+	ts.Equal(recorded, replayed)
+	ts.Zero(recorder.NotReplayed())
+}
+
+func (ts *TSRecorder) TestReplayWithoutInteractionReturnsError() {
+	ts.NoError((&Cassette{}).Save(ts.path))
+	recorder := ts.recorder(Options{})
+	request, err := http.NewRequest(http.MethodGet, deadURLTest+"/v1/organisation/accounts", nil)
+	ts.NoError(err)
+	_, err = recorder.RoundTrip(request)
+	ts.ErrorContains(err, "no interaction left in cassette "+ts.path+" for GET /v1/organisation/accounts")
+}
+
+func (ts *TSRecorder) TestReplayUsesEveryInteractionOnceInOrder() {
+	ts.NoError((&Cassette{Interactions: []Interaction{
+		{Request{Method: http.MethodGet, URL: "http://api/a"}, Response{StatusCode: http.StatusTooManyRequests}},
+		{Request{Method: http.MethodGet, URL: "http://api/a"}, Response{StatusCode: http.StatusOK, Body: "ok"}},
+	}}).Save(ts.path))
+	recorder := ts.recorder(Options{})
+
+	ts.Equal(http.StatusTooManyRequests, ts.send(recorder, http.MethodGet, deadURLTest+"/a", "", "").StatusCode)
+	response := ts.send(recorder, http.MethodGet, deadURLTest+"/a", "", "")
+	ts.Equal(http.StatusOK, response.StatusCode)
+	ts.Equal("200 OK", response.Status)
+	ts.Equal("ok", ts.readAll(response))
+
+	request, err := http.NewRequest(http.MethodGet, deadURLTest+"/a", nil)
+	ts.NoError(err)
+	_, err = recorder.RoundTrip(request)
+	ts.Error(err)
+}
+
+func (ts *TSRecorder) TestReplayMatchesMethodPathQueryAndBody() {
+	ts.NoError((&Cassette{Interactions: []Interaction{{
+		Request: Request{
+			Method: http.MethodPost,
+			URL:    "http://api/accounts?b=2&a=1",
+			Body:   `{"data":{"id":"1","type":"accounts"}}`,
+		},
+		Response: Response{StatusCode: http.StatusCreated},
+	}}}).Save(ts.path))
+
+	for _, request := range []struct{ method, url, body string }{
+		{http.MethodPut, deadURLTest + "/accounts?a=1&b=2", `{"data":{"id":"1","type":"accounts"}}`},
+		{http.MethodPost, deadURLTest + "/other?a=1&b=2", `{"data":{"id":"1","type":"accounts"}}`},
+		{http.MethodPost, deadURLTest + "/accounts?a=1", `{"data":{"id":"1","type":"accounts"}}`},
+		{http.MethodPost, deadURLTest + "/accounts?a=1&b=2", `{"data":{"id":"2","type":"accounts"}}`},
+	} {
+		recorder := ts.recorder(Options{})
+		httpRequest, err := http.NewRequest(request.method, request.url, strings.NewReader(request.body))
+		ts.NoError(err)
+		_, err = recorder.RoundTrip(httpRequest)
+		ts.Error(err, request)
+	}
+
+	recorder := ts.recorder(Options{})
+	response := ts.send(recorder, http.MethodPost, deadURLTest+"/accounts?a=1&b=2", "",
+		"{\n  \"data\": {\"type\": \"accounts\", \"id\": \"1\"}\n}")
+	ts.Equal(http.StatusCreated, response.StatusCode)
+}
+
+func (ts *TSRecorder) TestRecordRedactsHeadersAndBodyFields() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Session", "session-1")
+		w.Write([]byte(`{"access_token": "secret-token", "expires_in": 3600}`))
+	}))
+	defer server.Close()
+	recorder := ts.recorder(Options{
+		Mode:             ModeRecord,
+		RedactHeaders:    []string{"x-session", "X-Api-Key"},
+		RedactBodyFields: []string{"client_secret", "access_token"},
+	})
+
+	request, err := http.NewRequest(http.MethodPost, server.URL+"/token",
+		strings.NewReader("grant_type=client_credentials&client_secret=secret&client_id=id"))
+	ts.NoError(err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "Basic secret")
+	request.Header.Set("X-Api-Key", "secret-key")
+	response, err := recorder.RoundTrip(request)
+	ts.Require().NoError(err)
+	ts.JSONEq(`{"access_token": "secret-token", "expires_in": 3600}`, ts.readAll(response))
+	ts.Equal("session-1", response.Header.Get("X-Session"))
+	ts.NoError(recorder.Save())
+
+	cassette, err := Load(ts.path)
+	ts.Require().NoError(err)
+	ts.Require().Len(cassette.Interactions, 1)
+	interaction := cassette.Interactions[0]
+	ts.Equal(Redacted, interaction.Request.Header.Get("Authorization"))
+	ts.Equal(Redacted, interaction.Request.Header.Get("X-Api-Key"))
+	ts.Equal("client_id=id&client_secret=REDACTED&grant_type=client_credentials", interaction.Request.Body)
+	ts.Equal(Redacted, interaction.Response.Header.Get("X-Session"))
+	ts.Equal(`{"access_token":"REDACTED","expires_in":3600}`, interaction.Response.Body)
+
+	replayer := ts.recorder(Options{RedactBodyFields: []string{"client_secret"}})
+	replayed := ts.send(replayer, http.MethodPost, deadURLTest+"/token", "application/x-www-form-urlencoded",
+		"client_id=id&grant_type=client_credentials&client_secret=other")
+	ts.Equal(http.StatusOK, replayed.StatusCode)
+}
+
+func (ts *TSRecorder) TestRecordDecompressesGzipBodies() {
+	compress := func(data string) []byte {
+		buffer := &bytes.Buffer{}
+		writer := gzip.NewWriter(buffer)
+		writer.Write([]byte(data))
+		writer.Close()
+		return buffer.Bytes()
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compress(`{"data": []}`))
+	}))
+	defer server.Close()
+	recorder := ts.recorder(Options{Mode: ModeRecord})
+
+	request, err := http.NewRequest(http.MethodPost, server.URL+"/accounts", bytes.NewReader(compress(`{"data": {}}`)))
+	ts.NoError(err)
+	request.Header.Set("Content-Encoding", "gzip")
+	request.Header.Set("Accept-Encoding", "gzip")
+	response, err := recorder.RoundTrip(request)
+	ts.Require().NoError(err)
+	ts.Equal("gzip", response.Header.Get("Content-Encoding"))
+	ts.Equal(string(compress(`{"data": []}`)), ts.readAll(response))
+	ts.NoError(recorder.Save())
+
+	cassette, err := Load(ts.path)
+	ts.Require().NoError(err)
+	interaction := cassette.Interactions[0]
+	ts.Equal(`{"data":{}}`, interaction.Request.Body)
+	ts.Equal(`{"data":[]}`, interaction.Response.Body)
+	ts.Empty(interaction.Response.Header.Get("Content-Encoding"))
+}
+
+func (ts *TSRecorder) TestNewInReplayWithoutCassetteReturnsError() {
+	recorder, err := New(ts.path, Options{Mode: ModeReplay})
+	ts.ErrorContains(err, "failed reading cassette")
+	ts.Nil(recorder)
+}
+
+func (ts *TSRecorder) TestNewWithInvalidModeReturnsError() {
+	recorder, err := New(ts.path, Options{Mode: Mode(7)})
+	ts.EqualError(err, "invalid cassette mode 7")
+	ts.Nil(recorder)
+}
+
+func (ts *TSRecorder) TestSaveInReplayDoesNothing() {
+	ts.NoError((&Cassette{}).Save(ts.path))
+	recorder := ts.recorder(Options{})
+	ts.NoError(recorder.Save())
+}
+
+func (ts *TSRecorder) TestLoadInvalidCassetteReturnsError() {
+	ts.NoError(os.MkdirAll(filepath.Dir(ts.path), 0o755))
+	ts.NoError(os.WriteFile(ts.path, []byte("{"), 0o644))
+	_, err := Load(ts.path)
+	ts.ErrorContains(err, "failed decoding cassette")
+}
@@ -0,0 +1,69 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/url"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+// redactBody returns the body with the values of the redacted fields replaced.
+// JSON bodies are returned compact and with their fields sorted, and form bodies
+// with their fields sorted, so equal bodies are recorded the same way. Other
+// bodies are returned as they are.
+func (r *Recorder) redactBody(body []byte, contentType string) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == formContentType {
+		return r.redactForm(body)
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return body
+	}
+	redacted, err := json.Marshal(r.redactValue(value))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// redactValue replaces the values of the redacted fields of the JSON value, at
+// any depth.
+func (r *Recorder) redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, field := range typed {
+			if r.redactBodyFields[key] {
+				typed[key] = Redacted
+			} else {
+				typed[key] = r.redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = r.redactValue(item)
+		}
+	}
+	return value
+}
+
+func (r *Recorder) redactForm(body []byte) []byte {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return body
+	}
+	for key := range values {
+		if r.redactBodyFields[key] {
+			values.Set(key, Redacted)
+		}
+	}
+	return []byte(values.Encode())
+}
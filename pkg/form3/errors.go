@@ -0,0 +1,82 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/retry"
+)
+
+// temporaryStatusCodes are the status codes the Form3 API documentation says are
+// safe to retry after waiting a short amount of time.
+var temporaryStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+/*
+IsTemporary reports whether err is caused by a condition that may go away by
+itself: a 429, 500, 502, 503 or 504 response, a timeout, including an exceeded
+context deadline, a temporary DNS error or an error connecting to the API or
+reading its response. A cancelled context, TLS errors and invalid URLs are not
+temporary.
+
+Example: if form3.IsTemporary(err) { ... }
+*/
+func IsTemporary(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	apiError := &apierror.APIError{}
+	if errors.As(err, &apiError) {
+		return temporaryStatusCodes[apiError.StatusCode]
+	}
+
+	return retry.NetworkError(err)
+}
+
+/*
+IsRetryable reports whether the call that returned err can be done again with
+the same values expecting a different result. It is the case of the temporary
+errors (see IsTemporary), except the ones caused by a done context: the call
+must be done with a new context. A timeout of an attempt, like the Timeout of the
+http.Client, is retryable even if it wraps context.DeadlineExceeded, since the
+context of the call is not done.
+
+Example:
+
+	account, err := f3.Account().Fetch(id)
+	if form3.IsRetryable(err) {
+		delay, _ := form3.RetryAfter(err)
+		...
+	}
+*/
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) && !retry.NetworkError(err) {
+		return false
+	}
+	return IsTemporary(err)
+}
+
+/*
+RetryAfter returns the time the API advised to wait before retrying, and true, if
+the response of err had a Retry-After or exhausted X-RateLimit-* headers. It
+returns false otherwise.
+*/
+func RetryAfter(err error) (time.Duration, bool) {
+	var retryAfterError interface{ RetryAfter() time.Duration }
+	if errors.As(err, &retryAfterError) {
+		return retryAfterError.RetryAfter(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,135 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	errNetworkTest = &url.Error{Op: "Post", URL: rawBaseURLTest, Err: &net.OpError{
+		Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	errCanceledTest = &url.Error{Op: "Get", URL: rawBaseURLTest, Err: context.Canceled}
+	errDeadlineTest = fmt.Errorf("fake: %w", context.DeadlineExceeded)
+)
+
+type retryAfterErrorTest struct {
+	*apierror.APIError
+	retryAfter time.Duration
+}
+
+func (r retryAfterErrorTest) RetryAfter() time.Duration { return r.retryAfter }
+
+func (r retryAfterErrorTest) Unwrap() error { return r.APIError }
+
+type TSErrors struct{ suite.Suite }
+
+func TestRunErrorsSuite(t *testing.T) {
+	suite.Run(t, new(TSErrors))
+}
+
+func (ts *TSErrors) TestTemporaryStatusCodesAreTemporaryAndRetryable() {
+	for statusCode := range temporaryStatusCodes {
+		err := fmt.Errorf("wrapped: %w", &apierror.APIError{StatusCode: statusCode})
+		ts.True(IsTemporary(err), statusCode)
+		ts.True(IsRetryable(err), statusCode)
+	}
+}
+
+func (ts *TSErrors) TestOtherStatusCodesAreNotTemporaryNorRetryable() {
+	for _, statusCode := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict} {
+		err := &apierror.APIError{StatusCode: statusCode}
+		ts.False(IsTemporary(err), statusCode)
+		ts.False(IsRetryable(err), statusCode)
+	}
+}
+
+func (ts *TSErrors) TestNetworkErrorIsTemporaryAndRetryable() {
+	ts.True(IsTemporary(errNetworkTest))
+	ts.True(IsRetryable(errNetworkTest))
+}
+
+func (ts *TSErrors) TestTimeoutAndTemporaryDNSErrorsAreTemporary() {
+	readError := &url.Error{Op: "Get", URL: rawBaseURLTest, Err: &net.OpError{
+		Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}}
+	timeoutError := &url.Error{Op: "Get", URL: rawBaseURLTest, Err: &net.OpError{
+		Op: "write", Net: "tcp", Err: &net.DNSError{IsTimeout: true}}}
+	dnsError := &url.Error{Op: "Get", URL: rawBaseURLTest, Err: &net.DNSError{IsTemporary: true}}
+	for _, err := range []error{readError, timeoutError, dnsError} {
+		ts.True(IsTemporary(err), err)
+		ts.True(IsRetryable(err), err)
+	}
+}
+
+func (ts *TSErrors) TestPermanentNetworkErrorsAreNotTemporaryNorRetryable() {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	_, certificateError := http.Get(server.URL)
+	ts.Require().Error(certificateError)
+	_, schemeError := http.Get("ftp://api.form3.tech/v1/organisation/accounts")
+	ts.Require().Error(schemeError)
+	dnsError := &url.Error{Op: "Get", URL: rawBaseURLTest, Err: &net.DNSError{IsNotFound: true}}
+
+	for _, err := range []error{certificateError, schemeError, dnsError} {
+		ts.False(IsTemporary(err), err)
+		ts.False(IsRetryable(err), err)
+	}
+}
+
+func (ts *TSErrors) TestCanceledContextIsNotTemporaryNorRetryable() {
+	ts.False(IsTemporary(errCanceledTest))
+	ts.False(IsRetryable(errCanceledTest))
+}
+
+func (ts *TSErrors) TestDeadlineExceededIsTemporaryButNotRetryable() {
+	ts.True(IsTemporary(errDeadlineTest))
+	ts.False(IsRetryable(errDeadlineTest))
+}
+
+func (ts *TSErrors) TestClientTimeoutIsTemporaryAndRetryable() {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &http.Client{Timeout: 10 * time.Millisecond}
+	_, err := client.Get(server.URL)
+	ts.Require().ErrorIs(err, context.DeadlineExceeded)
+	ts.True(IsTemporary(err))
+	ts.True(IsRetryable(err))
+}
+
+func (ts *TSErrors) TestOtherErrorsAreNotTemporaryNorRetryable() {
+	for _, err := range []error{nil, errors.New("invalid character")} {
+		ts.False(IsTemporary(err))
+		ts.False(IsRetryable(err))
+	}
+}
+
+func (ts *TSErrors) TestRetryAfterReturnsAdvisedDelay() {
+	err := fmt.Errorf("wrapped: %w", retryAfterErrorTest{
+		APIError:   &apierror.APIError{StatusCode: http.StatusTooManyRequests},
+		retryAfter: 3 * time.Second,
+	})
+	delay, ok := RetryAfter(err)
+	ts.True(ok)
+	ts.Equal(3*time.Second, delay)
+	ts.True(IsRetryable(err))
+}
+
+func (ts *TSErrors) TestRetryAfterWithoutAdviceReturnsFalse() {
+	delay, ok := RetryAfter(&apierror.APIError{StatusCode: http.StatusTooManyRequests})
+	ts.False(ok)
+	ts.Zero(delay)
+}
@@ -6,19 +6,36 @@ import (
 )
 
 type Form3 struct {
-	configuration Configuration
+	configuration form3Config
 	account       *account.Account
 }
 
 /*
-New returns a initialized pointer of Form3
+New returns a initialized pointer of Form3, configured with the options. It
+returns an error if an option is not valid, or the options conflict.
 
 This is the entry point of the library.
+
+Example:
+
+	form3.New(form3.WithTimeout(10*time.Second), form3.WithUserAgent("payments-service/1.4"))
 */
-func New() *Form3 {
-	return &Form3{
-		configuration: configuration.New(),
+func New(opts ...Option) (*Form3, error) {
+	chosen := &options{}
+	for _, opt := range opts {
+		if err := opt(chosen); err != nil {
+			return nil, err
+		}
 	}
+
+	config := configuration.New()
+	if err := chosen.apply(config); err != nil {
+		return nil, err
+	}
+
+	return &Form3{
+		configuration: config,
+	}, nil
 }
 
 /*
@@ -57,9 +74,42 @@ func (f *Form3) ConfigurationByEnv() error {
 	return nil
 }
 
+/*
+ConfigurationFromFile initializes form3 with a profile of the YAML or JSON file
+in path. The file holds named profiles, like local, staging and production, each
+with the base URL, the resource paths, the organisation ID, the credentials and
+the HTTP tuning. The credentials only reference the secrets: the file of the
+private key and the environment variable of the client secret. Every key can be
+overridden with a FORM3_* environment variable, like FORM3_BASE_URL or
+FORM3_HTTP_TIMEOUT. It returns an error if the file or the profile is not valid,
+or if the profile has credentials and they were also set with the options of New.
+
+Example: form3.ConfigurationFromFile("form3.yaml", "staging")
+
+For the format of the file consult the README.
+*/
+func (f *Form3) ConfigurationFromFile(path, profile string) error {
+	if err := f.configuration.InitializeFromFile(path, profile); err != nil {
+		return err
+	}
+
+	f.initializeForm3()
+
+	return nil
+}
+
+/*
+OrganisationID returns the organisation ID of the profile read with
+ConfigurationFromFile, to be used in the accounts created. It is empty otherwise.
+*/
+func (f *Form3) OrganisationID() string {
+	return f.configuration.OrganisationID()
+}
+
 /*
 Account returns a pointer of account.Account. It requires the configuration to
-be previously set, either by value or by env. It will return nil otherwise.
+be previously set, either by value, by env or from a file. It will return nil otherwise.
+It implements account.AccountService, to depend on the interface instead.
 
 For account.Account consult its documentation, and Form3 API documentation.
 */
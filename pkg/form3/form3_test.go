@@ -5,6 +5,9 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/AdanJSuarez/form3/internal/client/httpclient"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/retry"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -17,7 +20,7 @@ const (
 var (
 	form3Test         *Form3
 	baseURLTest, _    = url.ParseRequestURI(rawBaseURLTest)
-	mockConfiguration *MockConfiguration
+	mockConfiguration *mockForm3Config
 )
 
 type TSForm3 struct{ suite.Suite }
@@ -27,8 +30,10 @@ func TestRunForm3Suite(t *testing.T) {
 }
 
 func (ts *TSForm3) BeforeTest(_, _ string) {
-	mockConfiguration = NewMockConfiguration(ts.T())
-	form3Test = New()
+	mockConfiguration = newMockForm3Config(ts.T())
+	var err error
+	form3Test, err = New()
+	ts.NoError(err)
 	ts.IsType(new(Form3), form3Test)
 	form3Test.configuration = mockConfiguration
 }
@@ -37,6 +42,12 @@ func (ts *TSForm3) TestValidConfigurationReturnsNoError() {
 	mockConfiguration.On("InitializeByValue", mock.Anything, mock.Anything).Return(nil)
 	mockConfiguration.On("AccountPath").Return(accountPath)
 	mockConfiguration.On("BaseURL").Return(baseURLTest)
+	mockConfiguration.On("RetryPolicy").Return(retry.DefaultPolicy())
+	mockConfiguration.On("StatusHandlers").Return(map[int]apierror.StatusHandler{})
+	mockConfiguration.On("CompressionThreshold").Return(0)
+	mockConfiguration.On("MutateRetries").Return(3)
+	mockConfiguration.On("Authenticator").Return(nil)
+	mockConfiguration.On("HTTPSettings").Return(httpclient.Settings{})
 	err := form3Test.ConfigurationByValue(rawBaseURLTest, accountPath)
 	ts.NoError(err)
 }
@@ -44,7 +55,7 @@ func (ts *TSForm3) TestValidConfigurationReturnsNoError() {
 func (ts *TSForm3) TestInvalidConfigurationReturnsError() {
 	mockConfiguration.On("InitializeByValue", mock.Anything,
 		mock.Anything).Return(fmt.Errorf("fake error"))
-	f3Test := New()
+	f3Test, _ := New()
 	f3Test.configuration = mockConfiguration
 
 	err := f3Test.ConfigurationByValue(rawBaseURLTest, accountPath)
@@ -55,6 +66,12 @@ func (ts *TSForm3) TestOnValidConfigReturnsAccountObject() {
 	mockConfiguration.On("InitializeByValue", mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
 	mockConfiguration.On("AccountPath").Return(accountPath)
 	mockConfiguration.On("BaseURL").Return(baseURLTest)
+	mockConfiguration.On("RetryPolicy").Return(retry.DefaultPolicy())
+	mockConfiguration.On("StatusHandlers").Return(map[int]apierror.StatusHandler{})
+	mockConfiguration.On("CompressionThreshold").Return(0)
+	mockConfiguration.On("MutateRetries").Return(3)
+	mockConfiguration.On("Authenticator").Return(nil)
+	mockConfiguration.On("HTTPSettings").Return(httpclient.Settings{})
 
 	err := form3Test.ConfigurationByValue("fakeURL", accountPath)
 	ts.NoError(err)
@@ -65,7 +82,7 @@ func (ts *TSForm3) TestOnValidConfigReturnsAccountObject() {
 func (ts *TSForm3) TestOnInvalidConfigReturnsNilAccount() {
 	mockConfiguration.On("InitializeByValue", mock.AnythingOfType("string"),
 		mock.AnythingOfType("string")).Return(fmt.Errorf("fake error"))
-	f3Test := New()
+	f3Test, _ := New()
 	f3Test.configuration = mockConfiguration
 
 	err := f3Test.ConfigurationByValue(rawBaseURLTest, accountPath)
@@ -74,10 +91,16 @@ func (ts *TSForm3) TestOnInvalidConfigReturnsNilAccount() {
 }
 
 func (ts *TSForm3) TestInvalidConfigurationByEnvReturnsError() {
-	mockConfiguration = new(MockConfiguration)
+	mockConfiguration = new(mockForm3Config)
 	mockConfiguration.On("InitializeByEnv").Return(fmt.Errorf("not implemented"))
 	mockConfiguration.On("AccountPath").Return(accountPath)
 	mockConfiguration.On("BaseURL").Return(baseURLTest)
+	mockConfiguration.On("RetryPolicy").Return(retry.DefaultPolicy())
+	mockConfiguration.On("StatusHandlers").Return(map[int]apierror.StatusHandler{})
+	mockConfiguration.On("CompressionThreshold").Return(0)
+	mockConfiguration.On("MutateRetries").Return(3)
+	mockConfiguration.On("Authenticator").Return(nil)
+	mockConfiguration.On("HTTPSettings").Return(httpclient.Settings{})
 	form3Test.configuration = mockConfiguration
 	err := form3Test.ConfigurationByEnv()
 	ts.Error(err)
@@ -87,8 +110,38 @@ func (ts *TSForm3) TestValidConfigurationByEnvReturnsAccount() {
 	mockConfiguration.On("InitializeByEnv").Return(nil)
 	mockConfiguration.On("AccountPath").Return(accountPath)
 	mockConfiguration.On("BaseURL").Return(baseURLTest)
+	mockConfiguration.On("RetryPolicy").Return(retry.DefaultPolicy())
+	mockConfiguration.On("StatusHandlers").Return(map[int]apierror.StatusHandler{})
+	mockConfiguration.On("CompressionThreshold").Return(0)
+	mockConfiguration.On("MutateRetries").Return(3)
+	mockConfiguration.On("Authenticator").Return(nil)
+	mockConfiguration.On("HTTPSettings").Return(httpclient.Settings{})
 
 	err := form3Test.ConfigurationByEnv()
 	ts.NoError(err)
 	ts.NotNil(form3Test.Account())
 }
+
+func (ts *TSForm3) TestValidConfigurationFromFileReturnsAccount() {
+	mockConfiguration.On("InitializeFromFile", "form3.yaml", "staging").Return(nil)
+	mockConfiguration.On("AccountPath").Return(accountPath)
+	mockConfiguration.On("BaseURL").Return(baseURLTest)
+	mockConfiguration.On("RetryPolicy").Return(retry.DefaultPolicy())
+	mockConfiguration.On("StatusHandlers").Return(map[int]apierror.StatusHandler{})
+	mockConfiguration.On("CompressionThreshold").Return(0)
+	mockConfiguration.On("MutateRetries").Return(3)
+	mockConfiguration.On("Authenticator").Return(nil)
+	mockConfiguration.On("HTTPSettings").Return(httpclient.Settings{})
+	mockConfiguration.On("OrganisationID").Return("fakeOrganisationID")
+
+	ts.NoError(form3Test.ConfigurationFromFile("form3.yaml", "staging"))
+	ts.NotNil(form3Test.Account())
+	ts.Equal("fakeOrganisationID", form3Test.OrganisationID())
+}
+
+func (ts *TSForm3) TestInvalidConfigurationFromFileReturnsError() {
+	mockConfiguration.On("InitializeFromFile", "form3.yaml", "staging").Return(fmt.Errorf("fake error"))
+
+	ts.Error(form3Test.ConfigurationFromFile("form3.yaml", "staging"))
+	ts.Nil(form3Test.Account())
+}
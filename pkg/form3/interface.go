@@ -1,12 +1,27 @@
 package form3
 
-import url "net/url"
+import (
+	url "net/url"
 
-//go:generate mockery --inpackage --name=Configuration
+	"github.com/AdanJSuarez/form3/internal/client/httpclient"
+	"github.com/AdanJSuarez/form3/internal/client/request"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/retry"
+)
 
-type Configuration interface {
+//go:generate mockery --inpackage --name=form3Config
+
+type form3Config interface {
 	BaseURL() *url.URL
 	AccountPath() string
+	RetryPolicy() retry.Policy
+	StatusHandlers() map[int]apierror.StatusHandler
+	CompressionThreshold() int
+	MutateRetries() int
+	Authenticator() request.Authenticator
+	HTTPSettings() httpclient.Settings
 	InitializeByValue(rawBaseURL, accountPath string) error
 	InitializeByEnv() error
+	InitializeFromFile(path, profile string) error
+	OrganisationID() string
 }
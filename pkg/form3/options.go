@@ -0,0 +1,312 @@
+package form3
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AdanJSuarez/form3/internal/client/httpclient"
+	"github.com/AdanJSuarez/form3/internal/configuration"
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/retry"
+	"github.com/AdanJSuarez/form3/pkg/signer"
+)
+
+const (
+	nilHTTPClientError       = "http client cannot be nil"
+	nilTransportError        = "transport cannot be nil"
+	nilLoggerError           = "logger cannot be nil"
+	emptyUserAgentError      = "user agent cannot be empty"
+	nonPositiveTimeoutError  = "timeout must be positive, got %v"
+	maxConnectionsError      = "max connections must be positive, got %d"
+	nilSignerError           = "signer cannot be nil"
+	credentialsConflictError = "signatures and client credentials cannot be combined"
+)
+
+// Logger logs the attempts of the requests. The standard *log.Logger is a Logger.
+type Logger = httpclient.Logger
+
+// Option configures the Form3 returned by New.
+type Option func(*options) error
+
+type options struct {
+	retryPolicy          *retry.Policy
+	httpSettings         httpclient.Settings
+	statusHandlers       map[int]apierror.StatusHandler
+	compressionThreshold int
+	mutateRetries        *int
+	signer               signer.Signer
+	clientCredentials    *clientCredentials
+}
+
+type clientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+}
+
+// apply sets the options chosen in the configuration. It returns an error if an
+// option is not valid, or the options conflict.
+func (o *options) apply(config *configuration.Configuration) error {
+	if o.signer != nil && o.clientCredentials != nil {
+		return fmt.Errorf(credentialsConflictError)
+	}
+
+	if o.retryPolicy != nil {
+		if err := config.SetRetryPolicy(*o.retryPolicy); err != nil {
+			return err
+		}
+	}
+	if err := config.SetHTTPSettings(o.httpSettings); err != nil {
+		return err
+	}
+	for statusCode, statusHandler := range o.statusHandlers {
+		if err := config.SetStatusHandler(statusCode, statusHandler); err != nil {
+			return err
+		}
+	}
+	if err := config.SetCompressionThreshold(o.compressionThreshold); err != nil {
+		return err
+	}
+	if o.mutateRetries != nil {
+		if err := config.SetMutateRetries(*o.mutateRetries); err != nil {
+			return err
+		}
+	}
+	if o.signer != nil {
+		return config.SetSigner(o.signer)
+	}
+	if o.clientCredentials != nil {
+		return config.SetClientCredentials(o.clientCredentials.tokenURL, o.clientCredentials.clientID,
+			o.clientCredentials.clientSecret)
+	}
+	return nil
+}
+
+/*
+WithHTTPClient makes the requests be sent with the client, instead of with one
+built by the library. It cannot be combined with WithTransport, WithTimeout or
+WithMaxConnections, as they configure the client built by the library.
+
+Example: form3.New(form3.WithHTTPClient(myHTTPClient))
+*/
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) error {
+		if client == nil {
+			return fmt.Errorf(nilHTTPClientError)
+		}
+		o.httpSettings.HTTPClient = client
+		return nil
+	}
+}
+
+/*
+WithTransport makes the requests be sent with the transport, instead of with a
+clone of http.DefaultTransport. It cannot be combined with WithMaxConnections,
+as it limits the connections of the default transport.
+
+Example: form3.New(form3.WithTransport(myTransport))
+*/
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) error {
+		if transport == nil {
+			return fmt.Errorf(nilTransportError)
+		}
+		o.httpSettings.Transport = transport
+		return nil
+	}
+}
+
+/*
+WithTimeout limits the time of every attempt of a request. By default it is 30
+seconds. The timeout must be positive.
+
+Example: form3.New(form3.WithTimeout(10 * time.Second))
+*/
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout <= 0 {
+			return fmt.Errorf(nonPositiveTimeoutError, timeout)
+		}
+		o.httpSettings.Timeout = timeout
+		return nil
+	}
+}
+
+/*
+WithRetryPolicy makes the requests be retried following the retry policy instead
+of retry.DefaultPolicy().
+
+Example: form3.New(form3.WithRetryPolicy(retry.NoRetries()))
+
+For retry.Policy consult its documentation.
+*/
+func WithRetryPolicy(retryPolicy retry.Policy) Option {
+	return func(o *options) error {
+		if err := retryPolicy.Validate(); err != nil {
+			return err
+		}
+		o.retryPolicy = &retryPolicy
+		return nil
+	}
+}
+
+/*
+WithUserAgent sets the User-Agent header of every request.
+
+Example: form3.New(form3.WithUserAgent("payments-service/1.4"))
+*/
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) error {
+		if userAgent == "" {
+			return fmt.Errorf(emptyUserAgentError)
+		}
+		o.httpSettings.UserAgent = userAgent
+		return nil
+	}
+}
+
+/*
+WithLogger logs, with the logger, the method, URL and outcome of every attempt
+of a request. The query of the URL and the headers are not logged.
+
+Example: form3.New(form3.WithLogger(log.Default()))
+*/
+func WithLogger(logger Logger) Option {
+	return func(o *options) error {
+		if logger == nil {
+			return fmt.Errorf(nilLoggerError)
+		}
+		o.httpSettings.Logger = logger
+		return nil
+	}
+}
+
+/*
+WithMaxConnections limits the connections opened to the API. By default they are
+100. The limit must be positive.
+
+Example: form3.New(form3.WithMaxConnections(20))
+*/
+func WithMaxConnections(maxConnections int) Option {
+	return func(o *options) error {
+		if maxConnections <= 0 {
+			return fmt.Errorf(maxConnectionsError, maxConnections)
+		}
+		o.httpSettings.MaxConnections = maxConnections
+		return nil
+	}
+}
+
+/*
+WithStatusHandler registers a handler that returns the error for the responses
+with the status code, instead of the default error. It overrides the default
+handling of that status code. New returns an error if the status code is not 4xx
+or 5xx, or the handler is nil.
+
+Example:
+
+	form3.New(form3.WithStatusHandler(http.StatusUnprocessableEntity, func(response *http.Response) error {
+		return ErrMyUnprocessable
+	}))
+
+For apierror.StatusHandler consult its documentation.
+*/
+func WithStatusHandler(statusCode int, statusHandler apierror.StatusHandler) Option {
+	return func(o *options) error {
+		if o.statusHandlers == nil {
+			o.statusHandlers = map[int]apierror.StatusHandler{}
+		}
+		o.statusHandlers[statusCode] = statusHandler
+		return nil
+	}
+}
+
+/*
+WithCompressionThreshold makes the request bodies of at least threshold bytes be
+sent compressed with gzip. By default, and with threshold zero, the bodies are
+not compressed. New returns an error if the threshold is negative.
+
+Example: form3.New(form3.WithCompressionThreshold(16 * 1024))
+*/
+func WithCompressionThreshold(threshold int) Option {
+	return func(o *options) error {
+		o.compressionThreshold = threshold
+		return nil
+	}
+}
+
+/*
+WithMutateRetries sets how many times the account Mutate fetches the account and
+applies the mutation again after a version conflict. By default it is 3, and zero
+disables the retries. New returns an error if it is negative.
+
+Example: form3.New(form3.WithMutateRetries(5))
+*/
+func WithMutateRetries(retries int) Option {
+	return func(o *options) error {
+		o.mutateRetries = &retries
+		return nil
+	}
+}
+
+/*
+WithSignatureKey makes every request be signed, as the Form3 API requires, with the
+RSA or ECDSA private key in PEM format. The keyID is the ID of the public key
+registered in Form3 for the organisation. It cannot be combined with
+WithClientCredentials.
+
+Example: form3.New(form3.WithSignatureKey("75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", privateKeyPEM))
+
+For the signature scheme consult Form3 API documentation.
+*/
+func WithSignatureKey(keyID string, privateKeyPEM []byte) Option {
+	return func(o *options) error {
+		pemSigner, err := signer.NewPEMSigner(keyID, privateKeyPEM)
+		if err != nil {
+			return err
+		}
+		o.signer = pemSigner
+		return nil
+	}
+}
+
+/*
+WithSigner makes every request be signed with the signer, instead of with a key
+held in memory as WithSignatureKey does. Use it to sign with keys held outside
+the process, like in an HSM or a KMS. It cannot be combined with
+WithClientCredentials.
+
+Example: form3.New(form3.WithSigner(signer.NewSocketSigner(keyID, signer.RSAAlgorithm, "unix", socketPath)))
+
+For signer.Signer consult its documentation.
+*/
+func WithSigner(requestSigner signer.Signer) Option {
+	return func(o *options) error {
+		if requestSigner == nil {
+			return fmt.Errorf(nilSignerError)
+		}
+		o.signer = requestSigner
+		return nil
+	}
+}
+
+/*
+WithClientCredentials makes every request be authenticated with a bearer token,
+as an alternative to the signatures. The token is obtained from the tokenURL with
+the OAuth2 client credentials grant and cached until shortly before it expires.
+If the API rejects a token with 401 Unauthorized, a new one is obtained and the
+request is sent once more. New returns an error if any value is empty.
+
+Example: form3.New(form3.WithClientCredentials("https://auth.example.com/oauth2/token", clientID, clientSecret))
+*/
+func WithClientCredentials(tokenURL, clientID, clientSecret string) Option {
+	return func(o *options) error {
+		o.clientCredentials = &clientCredentials{
+			tokenURL:     tokenURL,
+			clientID:     clientID,
+			clientSecret: clientSecret,
+		}
+		return nil
+	}
+}
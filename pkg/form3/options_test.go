@@ -0,0 +1,142 @@
+package form3
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AdanJSuarez/form3/pkg/retry"
+	"github.com/AdanJSuarez/form3/pkg/signer"
+	"github.com/stretchr/testify/suite"
+)
+
+type TSOptions struct{ suite.Suite }
+
+func TestRunTSOptions(t *testing.T) {
+	suite.Run(t, new(TSOptions))
+}
+
+func (ts *TSOptions) TestNewWithoutOptionsUsesDefaults() {
+	f3Test, err := New()
+	ts.NoError(err)
+	ts.Equal(retry.DefaultPolicy().MaxAttempts, f3Test.configuration.RetryPolicy().MaxAttempts)
+	ts.Zero(f3Test.configuration.HTTPSettings())
+}
+
+func (ts *TSOptions) TestNewWithOptionsSetsThem() {
+	logger := log.Default()
+	transport := &http.Transport{}
+	f3Test, err := New(
+		WithTransport(transport),
+		WithTimeout(10*time.Second),
+		WithRetryPolicy(retry.NoRetries()),
+		WithUserAgent("agent/1.0"),
+		WithLogger(logger),
+	)
+	ts.NoError(err)
+
+	settings := f3Test.configuration.HTTPSettings()
+	ts.Equal(transport, settings.Transport)
+	ts.Equal(10*time.Second, settings.Timeout)
+	ts.Equal("agent/1.0", settings.UserAgent)
+	ts.Equal(logger, settings.Logger)
+	ts.Equal(1, f3Test.configuration.RetryPolicy().MaxAttempts)
+}
+
+func (ts *TSOptions) TestNewWithHTTPClient() {
+	client := &http.Client{}
+	f3Test, err := New(WithHTTPClient(client), WithMaxConnections(1))
+	ts.ErrorContains(err, "cannot be combined")
+	ts.Nil(f3Test)
+
+	f3Test, err = New(WithHTTPClient(client), WithUserAgent("agent/1.0"))
+	ts.NoError(err)
+	ts.Same(client, f3Test.configuration.HTTPSettings().HTTPClient)
+}
+
+func (ts *TSOptions) TestNewWithConflictingOptionsReturnsError() {
+	_, err := New(WithHTTPClient(&http.Client{}), WithTransport(http.DefaultTransport))
+	ts.ErrorContains(err, "cannot be combined")
+	_, err = New(WithHTTPClient(&http.Client{}), WithTimeout(time.Second))
+	ts.ErrorContains(err, "cannot be combined")
+	_, err = New(WithTransport(http.DefaultTransport), WithMaxConnections(10))
+	ts.ErrorContains(err, "cannot be combined")
+}
+
+func (ts *TSOptions) TestNewWithInvalidOptionReturnsError() {
+	for _, opt := range []Option{
+		WithHTTPClient(nil),
+		WithTransport(nil),
+		WithTimeout(0),
+		WithTimeout(-time.Second),
+		WithRetryPolicy(retry.Policy{}),
+		WithUserAgent(""),
+		WithLogger(nil),
+		WithMaxConnections(0),
+		WithStatusHandler(http.StatusOK, func(*http.Response) error { return nil }),
+		WithStatusHandler(http.StatusConflict, nil),
+		WithCompressionThreshold(-1),
+		WithSignatureKey("fakeKeyID", []byte("fake key")),
+		WithSigner(nil),
+		WithClientCredentials("https://auth.fakeaddress/token", "", "fakeSecret"),
+	} {
+		f3Test, err := New(opt)
+		ts.Error(err)
+		ts.Nil(f3Test)
+	}
+}
+
+func (ts *TSOptions) TestNewWithStatusHandlerAndCompressionThresholdSetsThem() {
+	errTest := errors.New("fake precondition failed")
+	f3Test, err := New(
+		WithStatusHandler(http.StatusPreconditionFailed, func(*http.Response) error { return errTest }),
+		WithCompressionThreshold(1024),
+	)
+	ts.Require().NoError(err)
+
+	statusHandlers := f3Test.configuration.StatusHandlers()
+	ts.Len(statusHandlers, 1)
+	ts.ErrorIs(statusHandlers[http.StatusPreconditionFailed](nil), errTest)
+	ts.Equal(1024, f3Test.configuration.CompressionThreshold())
+}
+
+func (ts *TSOptions) TestNewWithMutateRetriesSetsThem() {
+	f3Test, err := New(WithMutateRetries(0))
+	ts.Require().NoError(err)
+	ts.Zero(f3Test.configuration.MutateRetries())
+
+	_, err = New(WithMutateRetries(-1))
+	ts.ErrorContains(err, "mutate retries cannot be negative")
+}
+
+func (ts *TSOptions) TestNewWithCredentialsSetsAuthenticator() {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keyBytes, _ := x509.MarshalECPrivateKey(key)
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes})
+
+	for _, opt := range []Option{
+		WithSignatureKey("fakeKeyID", privateKeyPEM),
+		WithSigner(signer.NewSocketSigner("fakeKeyID", "", "unix", "/fake.sock")),
+		WithClientCredentials("https://auth.fakeaddress/token", "fakeID", "fakeSecret"),
+	} {
+		f3Test, err := New(opt)
+		ts.Require().NoError(err)
+		ts.NotNil(f3Test.configuration.Authenticator())
+	}
+}
+
+func (ts *TSOptions) TestNewWithSignerAndClientCredentialsReturnsError() {
+	f3Test, err := New(
+		WithSigner(signer.NewSocketSigner("fakeKeyID", "", "unix", "/fake.sock")),
+		WithClientCredentials("https://auth.fakeaddress/token", "fakeID", "fakeSecret"),
+	)
+	ts.ErrorContains(err, "cannot be combined")
+	ts.Nil(f3Test)
+}
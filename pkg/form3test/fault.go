@@ -0,0 +1,238 @@
+package form3test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	retryAfterHeader    = "Retry-After"
+	contentLengthHeader = "Content-Length"
+	malformedBody       = `{"data": {"id": `
+	statusLineFmt       = "HTTP/1.1 %d %s\r\n"
+)
+
+/*
+A Fault answers a request to the Server instead of the fake API. serve answers
+the request as the fake API does, so a fault can change its response or delay it.
+
+The faults are injected with Server.Inject, for example to answer the next two
+account creations with 429 Too Many Requests and the third one as usual:
+
+	server.Inject(http.MethodPost, form3test.AccountPath,
+		form3test.Repeat(2, form3test.RetryAfter(http.StatusTooManyRequests, "1"))...)
+*/
+type Fault func(w http.ResponseWriter, r *http.Request, serve http.Handler)
+
+type injection struct {
+	method string
+	path   string
+	fault  Fault
+}
+
+// faults holds the faults injected, in order. It is safe for concurrent use.
+type faults struct {
+	mu         sync.Mutex
+	injections []injection
+}
+
+func (f *faults) add(method, path string, faults []Fault) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, fault := range faults {
+		f.injections = append(f.injections, injection{method: method, path: path, fault: fault})
+	}
+}
+
+// next removes and returns the first fault injected for the request, if any.
+func (f *faults) next(r *http.Request) (Fault, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, injected := range f.injections {
+		if (injected.method == "" || injected.method == r.Method) &&
+			(injected.path == "" || injected.path == r.URL.Path) {
+			f.injections = append(f.injections[:i], f.injections[i+1:]...)
+			return injected.fault, true
+		}
+	}
+	return nil, false
+}
+
+func (f *faults) pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.injections)
+}
+
+/*
+Inject makes the next requests with the method and path be answered by the faults,
+one request per fault, in order. An empty method or path matches any. Once the
+faults are used, the requests are answered by the fake API again.
+
+Example: server.Inject(http.MethodGet, "", form3test.Delay(5*time.Second))
+*/
+func (s *Server) Inject(method, path string, faults ...Fault) {
+	s.faults.add(method, path, faults)
+}
+
+// PendingFaults returns how many faults injected have not answered a request yet.
+func (s *Server) PendingFaults() int {
+	return s.faults.pending()
+}
+
+// Repeat returns the fault the given times, to inject it for several requests.
+func Repeat(times int, fault Fault) []Fault {
+	repeated := make([]Fault, 0, times)
+	for i := 0; i < times; i++ {
+		repeated = append(repeated, fault)
+	}
+	return repeated
+}
+
+// Status answers with the status code and an error body with its status text,
+// like the errors of the API: {"error_message": "Too Many Requests"}.
+func Status(statusCode int) Fault {
+	return Respond(statusCode, nil, errorBody(http.StatusText(statusCode)))
+}
+
+// RetryAfter answers as Status does, with the Retry-After header, in seconds or
+// as an HTTP date.
+func RetryAfter(statusCode int, retryAfter string) Fault {
+	header := http.Header{}
+	header.Set(retryAfterHeader, retryAfter)
+	return Respond(statusCode, header, errorBody(http.StatusText(statusCode)))
+}
+
+// Respond answers with the status code, the headers and the body. The content
+// type is the one of the API, unless the headers have another one.
+func Respond(statusCode int, header http.Header, body string) Fault {
+	return func(w http.ResponseWriter, _ *http.Request, _ http.Handler) {
+		w.Header().Set(contentTypeHeader, contentTypeValue)
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}
+}
+
+// MalformedJSON answers with the status code and a body that is not valid JSON.
+func MalformedJSON(statusCode int) Fault {
+	return Respond(statusCode, nil, malformedBody)
+}
+
+// Delay answers as the fake API does after the delay. It doesn't answer if the
+// client gives up waiting, like when it times out, but the request is not served
+// either.
+func Delay(delay time.Duration) Fault {
+	return func(w http.ResponseWriter, r *http.Request, serve http.Handler) {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-r.Context().Done():
+		case <-timer.C:
+			serve.ServeHTTP(w, r)
+		}
+	}
+}
+
+// DropConnection serves the request as the fake API does, but closes the
+// connection after sending the headers and half of the body of the response. If
+// the response has no body, the connection is closed without answering. The
+// change of the request, if any, is done anyway.
+func DropConnection() Fault {
+	return func(w http.ResponseWriter, r *http.Request, serve http.Handler) {
+		recorder := httptest.NewRecorder()
+		serve.ServeHTTP(recorder, r)
+
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		conn, buffer, err := hijacker.Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		body := recorder.Body.Bytes()
+		if len(body) == 0 {
+			return
+		}
+		writeResponseHead(buffer.Writer, recorder.Code, recorder.Header(), len(body))
+		buffer.Write(body[:len(body)/2])
+		buffer.Flush()
+	}
+}
+
+// Disconnect serves the request as the fake API does, so the change of the
+// request, if any, is done, but closes the connection without answering, as when
+// it is lost after the request reached the API.
+func Disconnect() Fault {
+	return func(w http.ResponseWriter, r *http.Request, serve http.Handler) {
+		serve.ServeHTTP(httptest.NewRecorder(), r)
+
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			conn.Close()
+		}
+	}
+}
+
+// Gzip answers as the fake API does, with the body compressed with gzip and the
+// Content-Encoding header, whatever the Accept-Encoding header of the request.
+func Gzip() Fault {
+	return func(w http.ResponseWriter, r *http.Request, serve http.Handler) {
+		recorder := httptest.NewRecorder()
+		serve.ServeHTTP(recorder, r)
+
+		for key, values := range recorder.Header() {
+			w.Header()[key] = values
+		}
+		if recorder.Body.Len() == 0 {
+			w.WriteHeader(recorder.Code)
+			return
+		}
+
+		compressed := &bytes.Buffer{}
+		writer := gzip.NewWriter(compressed)
+		writer.Write(recorder.Body.Bytes())
+		writer.Close()
+
+		w.Header().Set(contentEncodingHeader, gzipEncoding)
+		w.Header().Set(contentLengthHeader, strconv.Itoa(compressed.Len()))
+		w.WriteHeader(recorder.Code)
+		w.Write(compressed.Bytes())
+	}
+}
+
+func errorBody(message string) string {
+	body, _ := json.Marshal(errorResponse{ErrorMessage: message})
+	return string(body)
+}
+
+// writeResponseHead writes the status line and the headers of a response with
+// a body of the given length.
+func writeResponseHead(writer *bufio.Writer, statusCode int, header http.Header, length int) {
+	fmt.Fprintf(writer, statusLineFmt, statusCode, http.StatusText(statusCode))
+	header = header.Clone()
+	header.Set(contentLengthHeader, strconv.Itoa(length))
+	header.Write(writer)
+	writer.WriteString("\r\n")
+}
@@ -0,0 +1,151 @@
+package form3test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AdanJSuarez/form3/pkg/model"
+	"github.com/stretchr/testify/suite"
+)
+
+type TSFault struct {
+	suite.Suite
+	server *Server
+}
+
+func TestRunTSFault(t *testing.T) {
+	suite.Run(t, new(TSFault))
+}
+
+func (ts *TSFault) BeforeTest(_, _ string) {
+	ts.server = NewServer()
+	_, err := ts.server.store.Create(dataTest)
+	ts.Require().NoError(err)
+}
+
+func (ts *TSFault) AfterTest(_, _ string) {
+	ts.server.Close()
+}
+
+func (ts *TSFault) get(path string) *http.Response {
+	response, err := http.Get(ts.server.URL + path)
+	ts.Require().NoError(err)
+	return response
+}
+
+func (ts *TSFault) readAll(response *http.Response) []byte {
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	ts.NoError(err)
+	return body
+}
+
+func (ts *TSFault) TestFaultsAnswerMatchingRequestsInOrder() {
+	ts.server.Inject(http.MethodDelete, "", Status(http.StatusInternalServerError))
+	ts.server.Inject(http.MethodGet, AccountPath,
+		append(Repeat(2, RetryAfter(http.StatusTooManyRequests, "1")), Status(http.StatusBadGateway))...)
+	ts.Equal(4, ts.server.PendingFaults())
+
+	for _, statusCode := range []int{http.StatusTooManyRequests, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusOK} {
+		response := ts.get(AccountPath)
+		ts.readAll(response)
+		ts.Equal(statusCode, response.StatusCode)
+	}
+
+	response := ts.get(AccountPath + "/" + accountIDTest)
+	ts.readAll(response)
+	ts.Equal(http.StatusOK, response.StatusCode)
+	ts.Equal(1, ts.server.PendingFaults())
+}
+
+func (ts *TSFault) TestRetryAfterSetsHeaderAndErrorBody() {
+	ts.server.Inject("", "", RetryAfter(http.StatusServiceUnavailable, "2"))
+	response := ts.get(AccountPath)
+	ts.Equal(http.StatusServiceUnavailable, response.StatusCode)
+	ts.Equal("2", response.Header.Get(retryAfterHeader))
+	ts.JSONEq(`{"error_message": "Service Unavailable"}`, string(ts.readAll(response)))
+}
+
+func (ts *TSFault) TestRespondSetsHeadersAndBody() {
+	header := http.Header{}
+	header.Set(contentTypeHeader, "text/plain")
+	header.Set("X-Request-Id", "request-1")
+	ts.server.Inject("", "", Respond(http.StatusTeapot, header, "teapot"))
+	response := ts.get(AccountPath)
+	ts.Equal(http.StatusTeapot, response.StatusCode)
+	ts.Equal("text/plain", response.Header.Get(contentTypeHeader))
+	ts.Equal("request-1", response.Header.Get("X-Request-Id"))
+	ts.Equal("teapot", string(ts.readAll(response)))
+}
+
+func (ts *TSFault) TestMalformedJSONIsNotValidJSON() {
+	ts.server.Inject("", "", MalformedJSON(http.StatusOK))
+	response := ts.get(AccountPath)
+	ts.Equal(http.StatusOK, response.StatusCode)
+	ts.False(json.Valid(ts.readAll(response)))
+}
+
+func (ts *TSFault) TestDelayServesAfterDelay() {
+	ts.server.Inject("", "", Delay(50*time.Millisecond))
+	start := time.Now()
+	response := ts.get(AccountPath + "/" + accountIDTest)
+	ts.readAll(response)
+	ts.Equal(http.StatusOK, response.StatusCode)
+	ts.GreaterOrEqual(time.Since(start), 50*time.Millisecond)
+}
+
+func (ts *TSFault) TestDelayStopsWhenClientGivesUp() {
+	ts.server.Inject("", "", Delay(time.Minute))
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+	_, err := client.Get(ts.server.URL + AccountPath)
+	ts.Error(err)
+}
+
+func (ts *TSFault) TestDropConnectionCutsBody() {
+	ts.server.Inject("", "", DropConnection())
+	response := ts.get(AccountPath + "/" + accountIDTest)
+	ts.Equal(http.StatusOK, response.StatusCode)
+	defer response.Body.Close()
+	_, err := io.ReadAll(response.Body)
+	ts.ErrorIs(err, io.ErrUnexpectedEOF)
+}
+
+func (ts *TSFault) TestDropConnectionWithoutBodyClosesConnection() {
+	ts.server.Inject("", "", DropConnection())
+	request, err := http.NewRequest(http.MethodDelete, ts.server.URL+AccountPath+"/"+accountIDTest+"?version=0", nil)
+	ts.NoError(err)
+	_, err = http.DefaultClient.Do(request)
+	ts.Error(err)
+	ts.Empty(ts.server.Accounts())
+}
+
+func (ts *TSFault) TestDisconnectServesAndClosesConnection() {
+	ts.server.Inject("", "", Disconnect())
+	request, err := http.NewRequest(http.MethodDelete, ts.server.URL+AccountPath+"/"+accountIDTest+"?version=0", nil)
+	ts.NoError(err)
+	_, err = http.DefaultClient.Do(request)
+	ts.Error(err)
+	ts.Empty(ts.server.Accounts())
+}
+
+func (ts *TSFault) TestGzipCompressesBody() {
+	ts.server.Inject("", "", Gzip())
+	request, err := http.NewRequest(http.MethodGet, ts.server.URL+AccountPath+"/"+accountIDTest, nil)
+	ts.NoError(err)
+	request.Header.Set("Accept-Encoding", gzipEncoding)
+	response, err := http.DefaultClient.Do(request)
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+
+	ts.Equal(gzipEncoding, response.Header.Get(contentEncodingHeader))
+	reader, err := gzip.NewReader(response.Body)
+	ts.Require().NoError(err)
+	fetched := model.DataModel{}
+	ts.NoError(json.NewDecoder(reader).Decode(&fetched))
+	ts.Equal(dataTest, fetched.Data)
+}
@@ -0,0 +1,364 @@
+/*
+Package form3test provides an in-memory fake of the Form3 account API, to test
+the code using the library with plain go test, without the docker-compose stack.
+
+Example:
+
+	server := form3test.NewServer()
+	defer server.Close()
+
+	f3, _ := form3.New()
+	f3.ConfigurationByValue(server.URL, form3test.AccountPath)
+*/
+package form3test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AdanJSuarez/form3/internal/accountstore"
+	"github.com/AdanJSuarez/form3/pkg/model"
+)
+
+// AccountPath is the path of the accounts endpoints of the fake API.
+const AccountPath = "/v1/organisation/accounts"
+
+const (
+	contentTypeHeader     = "Content-Type"
+	contentEncodingHeader = "Content-Encoding"
+	contentTypeValue      = "application/vnd.api+json"
+	gzipEncoding          = "gzip"
+	versionParam          = "version"
+	pageNumberParam       = "page[number]"
+	pageSizeParam         = "page[size]"
+	filterPrefix          = "filter["
+	filterSuffix          = "]"
+	defaultPageSize       = 100
+	maxPageSize           = 1000
+	invalidPageMessageFmt = "invalid %s: %s"
+	invalidVersionFmt     = "invalid version number: %q"
+	missingVersionMessage = "validation failure list:\nvalidation failure list:\ndata.version in body is required"
+)
+
+// Server is an httptest.Server serving the accounts endpoints of the Form3 API
+// from memory:
+//
+//   - POST AccountPath creates an account, with version 0. It returns 400 with the
+//     list of validation failures if it is not valid, and 409 if the ID is in use.
+//   - GET AccountPath lists the accounts in creation order, by page[number] and
+//     page[size], keeping only the ones matching every filter[attribute]. It
+//     returns 400 for the attributes other than bank_id, bank_id_code,
+//     account_number, iban, country and customer_id.
+//   - GET AccountPath/{id} fetches an account, or returns 404.
+//   - PATCH AccountPath/{id} sets the attributes sent if the version sent is the
+//     current one, and increments it. It returns 409 otherwise, and 400 if the
+//     version is missing.
+//   - DELETE AccountPath/{id}?version={version} deletes an account if the version
+//     is the current one. It returns 409 otherwise.
+//
+// Invalid account IDs return 400, other paths 404 and other methods 405. The
+// request bodies can be compressed with gzip. It is safe for concurrent use.
+//
+// The faults injected with Inject answer the requests before the fake API, to
+// test how the errors of the API and the network are handled.
+type Server struct {
+	*httptest.Server
+	store  *accountstore.Store
+	faults faults
+}
+
+// NewServer starts and returns a Server without accounts. The caller should call
+// Close when finished, to shut it down.
+func NewServer() *Server {
+	server := NewUnstartedServer()
+	server.Start()
+	return server
+}
+
+// NewUnstartedServer returns a Server without accounts, but doesn't start it. The
+// caller can change its configuration, like TLS, before calling Start or StartTLS.
+func NewUnstartedServer() *Server {
+	server := &Server{store: accountstore.New()}
+	server.Server = httptest.NewUnstartedServer(http.HandlerFunc(server.serve))
+	return server
+}
+
+// Accounts returns the accounts stored, in creation order.
+func (s *Server) Accounts() []model.Data {
+	return s.store.All()
+}
+
+// accountResource is an account as returned by the API.
+type accountResource struct {
+	model.Data
+	CreatedOn  time.Time `json:"created_on"`
+	ModifiedOn time.Time `json:"modified_on"`
+}
+
+type accountResponse struct {
+	Data  accountResource `json:"data"`
+	Links model.Links     `json:"links"`
+}
+
+type listResponse struct {
+	Data  []accountResource `json:"data"`
+	Links model.Links       `json:"links"`
+}
+
+// patchRequest keeps the attributes sent as they are, to set only those. The
+// version is required, so a missing one is not taken as 0.
+type patchRequest struct {
+	Data struct {
+		Version    *int64          `json:"version"`
+		Attributes json.RawMessage `json:"attributes"`
+	} `json:"data"`
+}
+
+type errorResponse struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+// serve answers the request with the next fault injected for it, if any, or
+// with the fake API otherwise.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if fault, ok := s.faults.next(r); ok {
+		fault(w, r, http.HandlerFunc(s.serveAccounts))
+		return
+	}
+	s.serveAccounts(w, r)
+}
+
+func (s *Server) serveAccounts(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == AccountPath {
+		switch r.Method {
+		case http.MethodPost:
+			s.create(w, r)
+		case http.MethodGet:
+			s.list(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	id, ok := s.accountID(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.fetch(w, id)
+	case http.MethodPatch:
+		s.update(w, r, id)
+	case http.MethodDelete:
+		s.delete(w, r, id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) accountID(path string) (string, bool) {
+	id := strings.TrimPrefix(path, AccountPath+"/")
+	if id == path || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	dataModel := model.DataModel{}
+	if err := s.decodeBody(r, &dataModel); err != nil {
+		s.writeError(w, accountstore.NewFailure(http.StatusBadRequest, fmt.Sprintf(accountstore.InvalidBodyMessageFmt, err)))
+		return
+	}
+
+	account, err := s.store.Create(dataModel.Data)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeAccount(w, http.StatusCreated, account)
+}
+
+func (s *Server) fetch(w http.ResponseWriter, id string) {
+	account, err := s.store.Fetch(id)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeAccount(w, http.StatusOK, account)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	number, err := s.pageParam(query, pageNumberParam, 0)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	size, err := s.pageParam(query, pageSizeParam, defaultPageSize)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	if size < 1 || size > maxPageSize {
+		s.writeError(w, accountstore.NewFailure(http.StatusBadRequest,
+			fmt.Sprintf(invalidPageMessageFmt, pageSizeParam, query.Get(pageSizeParam))))
+		return
+	}
+
+	accounts, total, err := s.store.List(s.filters(query), number, size)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	response := listResponse{
+		Data:  make([]accountResource, 0, len(accounts)),
+		Links: s.listLinks(query, number, size, total),
+	}
+	for _, account := range accounts {
+		response.Data = append(response.Data, s.resource(account))
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, id string) {
+	patch := patchRequest{}
+	if err := s.decodeBody(r, &patch); err != nil {
+		s.writeError(w, accountstore.NewFailure(http.StatusBadRequest, fmt.Sprintf(accountstore.InvalidBodyMessageFmt, err)))
+		return
+	}
+
+	if patch.Data.Version == nil {
+		s.writeError(w, accountstore.NewFailure(http.StatusBadRequest, missingVersionMessage))
+		return
+	}
+
+	account, err := s.store.Update(id, *patch.Data.Version, patch.Data.Attributes)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeAccount(w, http.StatusOK, account)
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, id string) {
+	rawVersion := r.URL.Query().Get(versionParam)
+	version, err := strconv.ParseInt(rawVersion, 10, 64)
+	if err != nil {
+		s.writeError(w, accountstore.NewFailure(http.StatusBadRequest, fmt.Sprintf(invalidVersionFmt, rawVersion)))
+		return
+	}
+
+	if err := s.store.Delete(id, version); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) decodeBody(r *http.Request, value interface{}) error {
+	var body io.Reader = r.Body
+	if r.Header.Get(contentEncodingHeader) == gzipEncoding {
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		body = reader
+	}
+
+	return json.NewDecoder(body).Decode(value)
+}
+
+func (s *Server) pageParam(query url.Values, name string, defaultValue int) (int, error) {
+	rawValue := query.Get(name)
+	if rawValue == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(rawValue)
+	if err != nil || value < 0 {
+		return 0, accountstore.NewFailure(http.StatusBadRequest, fmt.Sprintf(invalidPageMessageFmt, name, rawValue))
+	}
+	return value, nil
+}
+
+// filters returns the value of every filter[attribute] by attribute.
+func (s *Server) filters(query url.Values) map[string]string {
+	filters := map[string]string{}
+	for key := range query {
+		if strings.HasPrefix(key, filterPrefix) && strings.HasSuffix(key, filterSuffix) {
+			filters[strings.TrimSuffix(strings.TrimPrefix(key, filterPrefix), filterSuffix)] = query.Get(key)
+		}
+	}
+	return filters
+}
+
+// listLinks returns the links of the page, keeping the filters and page size of
+// the query. There is no next link on the last page.
+func (s *Server) listLinks(query url.Values, number, size, total int) model.Links {
+	link := func(page int) string {
+		pageQuery := url.Values{}
+		for key, values := range query {
+			pageQuery[key] = values
+		}
+		pageQuery.Set(pageNumberParam, strconv.Itoa(page))
+		return AccountPath + "?" + pageQuery.Encode()
+	}
+
+	lastPage := 0
+	if total > 0 {
+		lastPage = (total - 1) / size
+	}
+	links := model.Links{
+		First: link(0),
+		Last:  link(lastPage),
+		Self:  link(number),
+	}
+	if number > 0 {
+		links.Prev = link(number - 1)
+	}
+	if number < lastPage {
+		links.Next = link(number + 1)
+	}
+	return links
+}
+
+func (s *Server) resource(account accountstore.Account) accountResource {
+	return accountResource{
+		Data:       account.Data,
+		CreatedOn:  account.CreatedOn,
+		ModifiedOn: account.ModifiedOn,
+	}
+}
+
+func (s *Server) writeAccount(w http.ResponseWriter, statusCode int, account accountstore.Account) {
+	s.writeJSON(w, statusCode, accountResponse{
+		Data:  s.resource(account),
+		Links: model.Links{Self: AccountPath + "/" + account.Data.ID},
+	})
+}
+
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	if apiFailure, ok := err.(*accountstore.Failure); ok {
+		statusCode = apiFailure.StatusCode
+	}
+	s.writeJSON(w, statusCode, errorResponse{ErrorMessage: err.Error()})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set(contentTypeHeader, contentTypeValue)
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(value)
+}
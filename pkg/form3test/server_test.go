@@ -0,0 +1,323 @@
+package form3test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/AdanJSuarez/form3/pkg/apierror"
+	"github.com/AdanJSuarez/form3/pkg/form3"
+	"github.com/AdanJSuarez/form3/pkg/model"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	organisationIDTest = "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c"
+	accountIDTest      = "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"
+	otherAccountIDTest = "0d209d7f-d07a-4542-947f-5885fddddae2"
+)
+
+var dataTest = model.Data{
+	ID:             accountIDTest,
+	OrganizationID: organisationIDTest,
+	Type:           "accounts",
+	Attributes: model.Attributes{
+		Country:      "GB",
+		BaseCurrency: "GBP",
+		BankID:       "400300",
+		BankIDCode:   "GBDSC",
+		Bic:          "NWBKGB22",
+		Name:         []string{"Jane Doe"},
+	},
+}
+
+type TSServer struct {
+	suite.Suite
+	server *Server
+}
+
+func TestRunTSServer(t *testing.T) {
+	suite.Run(t, new(TSServer))
+}
+
+func (ts *TSServer) BeforeTest(_, _ string) {
+	ts.server = NewServer()
+}
+
+func (ts *TSServer) AfterTest(_, _ string) {
+	ts.server.Close()
+}
+
+func (ts *TSServer) do(method, path string, body interface{}) (*http.Response, []byte) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		ts.Require().NoError(err)
+		reader = bytes.NewReader(raw)
+	}
+	request, err := http.NewRequest(method, ts.server.URL+path, reader)
+	ts.Require().NoError(err)
+	return ts.send(request)
+}
+
+func (ts *TSServer) send(request *http.Request) (*http.Response, []byte) {
+	response, err := http.DefaultClient.Do(request)
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+	raw, err := io.ReadAll(response.Body)
+	ts.Require().NoError(err)
+	return response, raw
+}
+
+func (ts *TSServer) create(data model.Data) {
+	response, _ := ts.do(http.MethodPost, AccountPath, model.DataModel{Data: data})
+	ts.Require().Equal(http.StatusCreated, response.StatusCode)
+}
+
+func (ts *TSServer) errorMessage(raw []byte) string {
+	body := errorResponse{}
+	ts.NoError(json.Unmarshal(raw, &body))
+	return body.ErrorMessage
+}
+
+func (ts *TSServer) TestCreateReturnsAccountWithVersionZero() {
+	data := dataTest
+	data.Version = 7
+	response, raw := ts.do(http.MethodPost, AccountPath, model.DataModel{Data: data})
+	ts.Equal(http.StatusCreated, response.StatusCode)
+
+	created := accountResponse{}
+	ts.NoError(json.Unmarshal(raw, &created))
+	ts.Equal(dataTest, created.Data.Data)
+	ts.False(created.Data.CreatedOn.IsZero())
+	ts.Equal(AccountPath+"/"+accountIDTest, created.Links.Self)
+	ts.Equal([]model.Data{dataTest}, ts.server.Accounts())
+}
+
+func (ts *TSServer) TestCreateWithDuplicateIDReturnsConflict() {
+	ts.create(dataTest)
+	response, raw := ts.do(http.MethodPost, AccountPath, model.DataModel{Data: dataTest})
+	ts.Equal(http.StatusConflict, response.StatusCode)
+	ts.Equal("Account cannot be created as it violates a duplicate constraint", ts.errorMessage(raw))
+}
+
+func (ts *TSServer) TestCreateInvalidAccountReturnsValidationList() {
+	data := dataTest
+	data.ID = ""
+	data.Type = "wrong"
+	data.Attributes.Country = "gb"
+	data.Attributes.Name = nil
+	data.Attributes.Status = "unknown"
+	response, raw := ts.do(http.MethodPost, AccountPath, model.DataModel{Data: data})
+	ts.Equal(http.StatusBadRequest, response.StatusCode)
+	ts.Equal("validation failure list:\nvalidation failure list:\n"+
+		"data.id in body is required\n"+
+		"data.type in body should be one of [accounts]\n"+
+		"data.attributes.country in body should match '^[A-Z]{2}$'\n"+
+		"data.attributes.status in body should be one of [pending confirmed failed closed]\n"+
+		"data.attributes.name in body is required", ts.errorMessage(raw))
+	ts.Empty(ts.server.Accounts())
+}
+
+func (ts *TSServer) TestCreateWithoutAttributesReturnsBadRequest() {
+	data := dataTest
+	data.Attributes = model.Attributes{}
+	response, raw := ts.do(http.MethodPost, AccountPath, model.DataModel{Data: data})
+	ts.Equal(http.StatusBadRequest, response.StatusCode)
+	ts.Contains(ts.errorMessage(raw), "data.attributes in body is required")
+}
+
+func (ts *TSServer) TestCreateWithMalformedBodyReturnsBadRequest() {
+	request, err := http.NewRequest(http.MethodPost, ts.server.URL+AccountPath, strings.NewReader("{"))
+	ts.NoError(err)
+	response, raw := ts.send(request)
+	ts.Equal(http.StatusBadRequest, response.StatusCode)
+	ts.Contains(ts.errorMessage(raw), "invalid body")
+}
+
+func (ts *TSServer) TestCreateWithGzipBody() {
+	raw, err := json.Marshal(model.DataModel{Data: dataTest})
+	ts.NoError(err)
+	compressed := &bytes.Buffer{}
+	writer := gzip.NewWriter(compressed)
+	writer.Write(raw)
+	writer.Close()
+
+	request, err := http.NewRequest(http.MethodPost, ts.server.URL+AccountPath, compressed)
+	ts.NoError(err)
+	request.Header.Set("Content-Encoding", "gzip")
+	response, _ := ts.send(request)
+	ts.Equal(http.StatusCreated, response.StatusCode)
+}
+
+func (ts *TSServer) TestFetch() {
+	ts.create(dataTest)
+	response, raw := ts.do(http.MethodGet, AccountPath+"/"+accountIDTest, nil)
+	ts.Equal(http.StatusOK, response.StatusCode)
+	fetched := model.DataModel{}
+	ts.NoError(json.Unmarshal(raw, &fetched))
+	ts.Equal(dataTest, fetched.Data)
+
+	response, raw = ts.do(http.MethodGet, AccountPath+"/"+otherAccountIDTest, nil)
+	ts.Equal(http.StatusNotFound, response.StatusCode)
+	ts.Equal("record "+otherAccountIDTest+" does not exist", ts.errorMessage(raw))
+
+	response, _ = ts.do(http.MethodGet, AccountPath+"/xxxxxx", nil)
+	ts.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func (ts *TSServer) TestListPagesAndFilters() {
+	ts.create(dataTest)
+	other := dataTest
+	other.ID = otherAccountIDTest
+	other.Attributes.AccountNumber = "41426819"
+	ts.create(other)
+
+	response, raw := ts.do(http.MethodGet, AccountPath+"?page%5Bnumber%5D=0&page%5Bsize%5D=1", nil)
+	ts.Equal(http.StatusOK, response.StatusCode)
+	page := model.ListDataModel{}
+	ts.NoError(json.Unmarshal(raw, &page))
+	ts.Equal([]model.Data{dataTest}, page.Data)
+	ts.Equal(AccountPath+"?page%5Bnumber%5D=1&page%5Bsize%5D=1", page.Links.Next)
+	ts.Equal(page.Links.Next, page.Links.Last)
+	ts.Empty(page.Links.Prev)
+
+	_, raw = ts.do(http.MethodGet, AccountPath+"?page%5Bnumber%5D=1&page%5Bsize%5D=1", nil)
+	page = model.ListDataModel{}
+	ts.NoError(json.Unmarshal(raw, &page))
+	ts.Equal([]model.Data{other}, page.Data)
+	ts.Empty(page.Links.Next)
+	ts.NotEmpty(page.Links.Prev)
+
+	_, raw = ts.do(http.MethodGet, AccountPath+"?filter%5Baccount_number%5D=41426819", nil)
+	page = model.ListDataModel{}
+	ts.NoError(json.Unmarshal(raw, &page))
+	ts.Equal([]model.Data{other}, page.Data)
+
+	response, _ = ts.do(http.MethodGet, AccountPath+"?page%5Bsize%5D=0", nil)
+	ts.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func (ts *TSServer) TestListFiltersByCustomerID() {
+	ts.create(dataTest)
+	other := dataTest
+	other.ID = otherAccountIDTest
+	other.Attributes.CustomerID = "fakeCustomerID"
+	ts.create(other)
+
+	response, raw := ts.do(http.MethodGet, AccountPath+"?filter%5Bcustomer_id%5D=fakeCustomerID", nil)
+	ts.Equal(http.StatusOK, response.StatusCode)
+	page := model.ListDataModel{}
+	ts.NoError(json.Unmarshal(raw, &page))
+	ts.Equal([]model.Data{other}, page.Data)
+}
+
+func (ts *TSServer) TestListWithUnknownFilterReturnsBadRequest() {
+	ts.create(dataTest)
+
+	response, raw := ts.do(http.MethodGet, AccountPath+"?filter%5Bname%5D=Jane", nil)
+	ts.Equal(http.StatusBadRequest, response.StatusCode)
+	ts.Equal("invalid filter: name", ts.errorMessage(raw))
+}
+
+func (ts *TSServer) TestPatchChecksVersion() {
+	ts.create(dataTest)
+	patch := map[string]interface{}{
+		"data": map[string]interface{}{
+			"version":    0,
+			"attributes": map[string]interface{}{"status": "closed"},
+		},
+	}
+	response, raw := ts.do(http.MethodPatch, AccountPath+"/"+accountIDTest, patch)
+	ts.Equal(http.StatusOK, response.StatusCode)
+	updated := model.DataModel{}
+	ts.NoError(json.Unmarshal(raw, &updated))
+	ts.Equal(int64(1), updated.Data.Version)
+	ts.Equal("closed", updated.Data.Attributes.Status)
+	ts.Equal(dataTest.Attributes.Bic, updated.Data.Attributes.Bic)
+
+	response, raw = ts.do(http.MethodPatch, AccountPath+"/"+accountIDTest, patch)
+	ts.Equal(http.StatusConflict, response.StatusCode)
+	ts.Equal("invalid version", ts.errorMessage(raw))
+}
+
+func (ts *TSServer) TestPatchWithoutVersionReturnsBadRequest() {
+	ts.create(dataTest)
+	patch := map[string]interface{}{
+		"data": map[string]interface{}{"attributes": map[string]interface{}{"status": "closed"}},
+	}
+	response, raw := ts.do(http.MethodPatch, AccountPath+"/"+accountIDTest, patch)
+	ts.Equal(http.StatusBadRequest, response.StatusCode)
+	ts.Contains(ts.errorMessage(raw), "data.version in body is required")
+	ts.Equal(int64(0), ts.server.Accounts()[0].Version)
+}
+
+func (ts *TSServer) TestPatchInvalidAttributesReturnsBadRequest() {
+	ts.create(dataTest)
+	patch := map[string]interface{}{
+		"data": map[string]interface{}{"version": 0, "attributes": map[string]interface{}{"country": "XXX"}},
+	}
+	response, _ := ts.do(http.MethodPatch, AccountPath+"/"+accountIDTest, patch)
+	ts.Equal(http.StatusBadRequest, response.StatusCode)
+	ts.Equal(int64(0), ts.server.Accounts()[0].Version)
+}
+
+func (ts *TSServer) TestDeleteChecksVersion() {
+	ts.create(dataTest)
+	response, _ := ts.do(http.MethodDelete, AccountPath+"/"+accountIDTest+"?version=1", nil)
+	ts.Equal(http.StatusConflict, response.StatusCode)
+	response, _ = ts.do(http.MethodDelete, AccountPath+"/"+accountIDTest, nil)
+	ts.Equal(http.StatusBadRequest, response.StatusCode)
+
+	response, _ = ts.do(http.MethodDelete, AccountPath+"/"+accountIDTest+"?version=0", nil)
+	ts.Equal(http.StatusNoContent, response.StatusCode)
+	ts.Empty(ts.server.Accounts())
+
+	response, _ = ts.do(http.MethodDelete, AccountPath+"/"+accountIDTest+"?version=0", nil)
+	ts.Equal(http.StatusNotFound, response.StatusCode)
+}
+
+func (ts *TSServer) TestUnknownRoutes() {
+	response, _ := ts.do(http.MethodGet, "/v1/organisation/account", nil)
+	ts.Equal(http.StatusNotFound, response.StatusCode)
+	response, _ = ts.do(http.MethodPut, AccountPath, nil)
+	ts.Equal(http.StatusMethodNotAllowed, response.StatusCode)
+	response, _ = ts.do(http.MethodPost, AccountPath+"/"+accountIDTest, nil)
+	ts.Equal(http.StatusMethodNotAllowed, response.StatusCode)
+}
+
+func (ts *TSServer) TestLibraryAgainstServer() {
+	f3, err := form3.New()
+	ts.Require().NoError(err)
+	ts.Require().NoError(f3.ConfigurationByValue(ts.server.URL, AccountPath))
+	accounts := f3.Account()
+
+	created, err := accounts.Create(model.DataModel{Data: dataTest})
+	ts.NoError(err)
+	ts.Equal(dataTest, created.Data)
+
+	_, err = accounts.Create(model.DataModel{Data: dataTest})
+	ts.True(errors.Is(err, apierror.ErrConflict))
+
+	updated, err := accounts.Update(accountIDTest, 0, model.Attributes{Status: "closed"})
+	ts.NoError(err)
+	ts.Equal(int64(1), updated.Data.Version)
+
+	invalid := dataTest
+	invalid.ID = otherAccountIDTest
+	invalid.Attributes.Country = "gb"
+	_, err = accounts.Create(model.DataModel{Data: invalid})
+	apiError := &apierror.APIError{}
+	ts.Require().True(errors.As(err, &apiError))
+	ts.Equal([]apierror.FieldError{{Field: "country", Rule: apierror.RulePattern,
+		Message: "data.attributes.country in body should match '^[A-Z]{2}$'"}}, apiError.FieldErrors)
+
+	ts.NoError(accounts.Delete(accountIDTest, 1))
+	_, err = accounts.Fetch(accountIDTest)
+	ts.True(errors.Is(err, apierror.ErrNotFound))
+}
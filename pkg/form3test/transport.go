@@ -0,0 +1,51 @@
+package form3test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/AdanJSuarez/form3/internal/accountstore"
+	"github.com/AdanJSuarez/form3/pkg/model"
+)
+
+/*
+Transport is an http.RoundTripper that answers the requests with the fake API of
+Server, from memory and without listening on a port. The host of the requests is
+ignored. It is safe for concurrent use.
+
+Example:
+
+	transport := form3test.NewTransport()
+	client := &http.Client{Transport: transport}
+*/
+type Transport struct {
+	server *Server
+}
+
+// NewTransport returns a Transport without accounts.
+func NewTransport() *Transport {
+	return &Transport{server: &Server{store: accountstore.New()}}
+}
+
+// Accounts returns the accounts stored, in creation order.
+func (t *Transport) Accounts() []model.Data {
+	return t.server.Accounts()
+}
+
+// RoundTrip answers the request with the fake API. It returns the error of the
+// context of the request if it is done.
+func (t *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body != nil {
+		defer request.Body.Close()
+	}
+	if err := request.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	recorder := httptest.NewRecorder()
+	t.server.serveAccounts(recorder, request)
+
+	response := recorder.Result()
+	response.Request = request
+	return response, nil
+}
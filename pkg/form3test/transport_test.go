@@ -0,0 +1,58 @@
+package form3test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/AdanJSuarez/form3/pkg/model"
+	"github.com/stretchr/testify/suite"
+)
+
+const transportURLTest = "http://form3test.invalid"
+
+type TSTransport struct {
+	suite.Suite
+	transport *Transport
+	client    *http.Client
+}
+
+func TestRunTSTransport(t *testing.T) {
+	suite.Run(t, new(TSTransport))
+}
+
+func (ts *TSTransport) BeforeTest(_, _ string) {
+	ts.transport = NewTransport()
+	ts.client = &http.Client{Transport: ts.transport}
+}
+
+func (ts *TSTransport) TestServesTheFakeAPIFromMemory() {
+	body, err := json.Marshal(model.DataModel{Data: dataTest})
+	ts.Require().NoError(err)
+	response, err := ts.client.Post(transportURLTest+AccountPath, contentTypeValue, bytes.NewReader(body))
+	ts.Require().NoError(err)
+	response.Body.Close()
+	ts.Equal(http.StatusCreated, response.StatusCode)
+
+	response, err = ts.client.Get(transportURLTest + AccountPath + "/" + accountIDTest)
+	ts.Require().NoError(err)
+	defer response.Body.Close()
+	raw, err := io.ReadAll(response.Body)
+	ts.Require().NoError(err)
+	ts.Equal(http.StatusOK, response.StatusCode)
+	ts.Contains(string(raw), accountIDTest)
+	ts.Len(ts.transport.Accounts(), 1)
+}
+
+func (ts *TSTransport) TestDoneContextReturnsContextError() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, transportURLTest+AccountPath, nil)
+	ts.Require().NoError(err)
+
+	_, err = ts.client.Do(request)
+	ts.ErrorIs(err, context.Canceled)
+}
@@ -24,6 +24,7 @@ type Attributes struct {
 	BaseCurrency            string   `json:"base_currency,omitempty"`
 	Bic                     string   `json:"bic,omitempty"`
 	Country                 string   `json:"country,omitempty"`
+	CustomerID              string   `json:"customer_id,omitempty"`
 	Iban                    string   `json:"iban,omitempty"`
 	JointAccount            bool     `json:"joint_account,omitempty"`
 	Name                    []string `json:"name,omitempty"`
@@ -31,3 +32,18 @@ type Attributes struct {
 	Status                  string   `json:"status,omitempty"`
 	Switched                bool     `json:"switched,omitempty"`
 }
+
+// Ref: https://www.api-docs.form3.tech/api/tutorials/getting-started/list-resources
+
+type ListDataModel struct {
+	Data  []Data `json:"data"`
+	Links Links  `json:"links,omitempty"`
+}
+
+type Links struct {
+	First string `json:"first,omitempty"`
+	Last  string `json:"last,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Self  string `json:"self,omitempty"`
+}
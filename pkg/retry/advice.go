@@ -0,0 +1,93 @@
+package retry
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ref: https://www.rfc-editor.org/rfc/rfc9110#field.retry-after
+// Ref: https://www.api-docs.form3.tech/api/schemes/fps-direct/introduction/rate-limiting
+
+const (
+	RetryAfterHeader         = "Retry-After"
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+
+	// Reset values bigger than this are Unix times, the smaller ones are seconds.
+	unixTimeThreshold = 1000000000
+	// Values bigger than this overflow a time.Duration, or the Unix time in
+	// nanoseconds, so they are not valid advice.
+	maxAdvisedSeconds = math.MaxInt64 / int64(time.Second)
+)
+
+/*
+AdvisedDelay returns the time the server asks to wait before sending the request
+again, and true, if the response headers contain such advice:
+
+  - Retry-After, either in seconds or as an HTTP-date.
+  - X-RateLimit-Reset when X-RateLimit-Remaining is 0, either in seconds or as a Unix time.
+
+A date in the past is an advice to retry right away, so it returns zero and true.
+Values too big for a time.Duration are not advice: it returns false for them.
+*/
+func AdvisedDelay(header http.Header, now time.Time) (time.Duration, bool) {
+	if header == nil {
+		return 0, false
+	}
+
+	if delay, ok := parseRetryAfter(header.Get(RetryAfterHeader), now); ok {
+		return delay, true
+	}
+
+	return parseRateLimitReset(header, now)
+}
+
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 || seconds > maxAdvisedSeconds {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return positive(date.Sub(now)), true
+}
+
+func parseRateLimitReset(header http.Header, now time.Time) (time.Duration, bool) {
+	remaining := strings.TrimSpace(header.Get(RateLimitRemainingHeader))
+	if remaining != "0" {
+		return 0, false
+	}
+
+	reset, err := strconv.ParseFloat(strings.TrimSpace(header.Get(RateLimitResetHeader)), 64)
+	// Written so NaN is rejected too.
+	if err != nil || !(reset >= 0 && reset <= float64(maxAdvisedSeconds)) {
+		return 0, false
+	}
+
+	if reset > unixTimeThreshold {
+		resetTime := time.Unix(0, int64(reset*float64(time.Second)))
+		return positive(resetTime.Sub(now)), true
+	}
+	return time.Duration(reset * float64(time.Second)), true
+}
+
+func positive(delay time.Duration) time.Duration {
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
@@ -0,0 +1,118 @@
+package retry
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var nowTest = time.Date(2022, time.December, 1, 10, 0, 0, 0, time.UTC)
+
+type TSAdvice struct{ suite.Suite }
+
+func TestRunTSAdvice(t *testing.T) {
+	suite.Run(t, new(TSAdvice))
+}
+
+func (ts *TSAdvice) TestRetryAfterInSeconds() {
+	header := http.Header{RetryAfterHeader: []string{"5"}}
+	delay, ok := AdvisedDelay(header, nowTest)
+	ts.True(ok)
+	ts.Equal(5*time.Second, delay)
+}
+
+func (ts *TSAdvice) TestRetryAfterAsHTTPDate() {
+	date := nowTest.Add(90 * time.Second).Format(http.TimeFormat)
+	header := http.Header{RetryAfterHeader: []string{date}}
+	delay, ok := AdvisedDelay(header, nowTest)
+	ts.True(ok)
+	ts.Equal(90*time.Second, delay)
+}
+
+func (ts *TSAdvice) TestRetryAfterDateInThePastReturnsZero() {
+	date := nowTest.Add(-time.Hour).Format(http.TimeFormat)
+	header := http.Header{RetryAfterHeader: []string{date}}
+	delay, ok := AdvisedDelay(header, nowTest)
+	ts.True(ok)
+	ts.Zero(delay)
+}
+
+func (ts *TSAdvice) TestInvalidRetryAfterReturnsFalse() {
+	for _, value := range []string{"", "soon", "-3"} {
+		header := http.Header{RetryAfterHeader: []string{value}}
+		_, ok := AdvisedDelay(header, nowTest)
+		ts.False(ok, value)
+	}
+}
+
+func (ts *TSAdvice) TestRetryAfterTooBigReturnsFalse() {
+	for _, value := range []string{"9223372037", "18446744073", "99999999999999999999"} {
+		header := http.Header{RetryAfterHeader: []string{value}}
+		delay, ok := AdvisedDelay(header, nowTest)
+		ts.False(ok, value)
+		ts.Zero(delay, value)
+	}
+}
+
+func (ts *TSAdvice) TestRetryAfterBiggestValueIsNotNegative() {
+	header := http.Header{RetryAfterHeader: []string{"9223372036"}}
+	delay, ok := AdvisedDelay(header, nowTest)
+	ts.True(ok)
+	ts.Equal(9223372036*time.Second, delay)
+}
+
+func (ts *TSAdvice) TestNilHeaderReturnsFalse() {
+	_, ok := AdvisedDelay(nil, nowTest)
+	ts.False(ok)
+}
+
+func (ts *TSAdvice) TestRateLimitResetInSecondsWhenExhausted() {
+	header := http.Header{}
+	header.Set(RateLimitLimitHeader, "100")
+	header.Set(RateLimitRemainingHeader, "0")
+	header.Set(RateLimitResetHeader, "3")
+	delay, ok := AdvisedDelay(header, nowTest)
+	ts.True(ok)
+	ts.Equal(3*time.Second, delay)
+}
+
+func (ts *TSAdvice) TestRateLimitResetAsUnixTimeWhenExhausted() {
+	header := http.Header{}
+	header.Set(RateLimitRemainingHeader, "0")
+	header.Set(RateLimitResetHeader, fmt.Sprint(nowTest.Add(time.Minute).Unix()))
+	delay, ok := AdvisedDelay(header, nowTest)
+	ts.True(ok)
+	ts.Equal(time.Minute, delay)
+}
+
+func (ts *TSAdvice) TestRateLimitResetTooBigReturnsFalse() {
+	for _, value := range []string{"9223372037", "18446744073", "1e300", "NaN", "+Inf"} {
+		header := http.Header{}
+		header.Set(RateLimitRemainingHeader, "0")
+		header.Set(RateLimitResetHeader, value)
+		delay, ok := AdvisedDelay(header, nowTest)
+		ts.False(ok, value)
+		ts.Zero(delay, value)
+	}
+}
+
+func (ts *TSAdvice) TestRateLimitNotExhaustedReturnsFalse() {
+	header := http.Header{}
+	header.Set(RateLimitRemainingHeader, "10")
+	header.Set(RateLimitResetHeader, "3")
+	_, ok := AdvisedDelay(header, nowTest)
+	ts.False(ok)
+}
+
+func (ts *TSAdvice) TestRetryAfterTakesPrecedenceOverRateLimit() {
+	header := http.Header{}
+	header.Set(RetryAfterHeader, "1")
+	header.Set(RateLimitRemainingHeader, "0")
+	header.Set(RateLimitResetHeader, "30")
+	delay, ok := AdvisedDelay(header, nowTest)
+	ts.True(ok)
+	ts.Equal(time.Second, delay)
+}
@@ -0,0 +1,238 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Ref: https://www.api-docs.form3.tech/api/schemes/sepa-instant-credit-transfer/introduction/timeouts/retry-strategy
+
+const (
+	defaultMaxAttempts = 4
+	defaultBaseDelay   = time.Second
+	defaultMultiplier  = 1.5
+	defaultMaxDelay    = 30 * time.Second
+	defaultMaxJitter   = 10 * time.Second
+	defaultMaxAdvised  = time.Minute
+
+	maxAttemptsError = "retry policy: max attempts must be at least 1, got %d"
+	delayError       = "retry policy: base delay, max delay, max jitter and max advised delay cannot be negative"
+	maxDelayError    = "retry policy: max delay %v is lower than base delay %v"
+	multiplierError  = "retry policy: multiplier must be at least 1, got %v"
+	jitterError      = "retry policy: unknown jitter strategy %d"
+
+	// Operations of the net.OpError that may succeed when tried again.
+	dialOp = "dial"
+	readOp = "read"
+)
+
+// Jitter is the strategy used to randomize the delay between attempts, so
+// clients failing at the same time do not retry at the same time.
+type Jitter int
+
+const (
+	// NoJitter waits exactly the computed delay.
+	NoJitter Jitter = iota
+	// FullJitter waits a random time between zero and the computed delay.
+	FullJitter
+	// EqualJitter waits half the computed delay plus a random time up to the other half.
+	EqualJitter
+	// AdditiveJitter waits the computed delay plus a random time up to MaxJitter.
+	AdditiveJitter
+)
+
+/*
+Policy defines when and how a failed request is retried. The delay before the
+retry n (starting at 1) is BaseDelay * Multiplier^n, limited to MaxDelay, and then
+randomized following the Jitter strategy.
+
+If the response to retry advises how long to wait (Retry-After or exhausted
+X-RateLimit-* headers, see AdvisedDelay), that time is waited instead. If it is
+longer than MaxAdvisedDelay, the request is not retried.
+
+A response is retried when its status code is in RetryableStatusCodes. An error
+sending the request (network error, timeout, ...) is retried when RetryableError
+returns true for it. Requests whose context is done are never retried. POST and
+PATCH requests are only retried after 429 responses, unless RetryNonIdempotent
+is set.
+*/
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	Multiplier  float64
+	MaxDelay    time.Duration
+	Jitter      Jitter
+	// MaxJitter is the upper limit of the random time added by AdditiveJitter.
+	MaxJitter time.Duration
+	// MaxAdvisedDelay is the longest delay advised by the server that is waited.
+	// Zero means no limit.
+	MaxAdvisedDelay      time.Duration
+	RetryableStatusCodes []int
+	// RetryableError decides if an error sending the request is retried. If nil,
+	// those errors are not retried.
+	RetryableError func(err error) bool
+	// RetryNonIdempotent makes POST and PATCH requests with an Idempotency-Key
+	// header be retried after errors sending them and after 5xx responses too.
+	// Without it they are only retried after 429 responses, as the API may have
+	// done the change already. Only set it if the API deduplicates the requests
+	// by their Idempotency-Key, or a retry may create an account twice.
+	RetryNonIdempotent bool
+}
+
+/*
+DefaultPolicy returns the policy used when none is set: up to 4 attempts with an
+exponential delay (base 1.5) starting at 1 second and up to 10 seconds of
+additive jitter, retrying transient network errors (see NetworkError) and the
+status codes 429, 500, 502, 503 and 504, as recommended by the Form3 API
+documentation. Delays advised by the server are waited up to 1 minute.
+*/
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:     defaultMaxAttempts,
+		BaseDelay:       defaultBaseDelay,
+		Multiplier:      defaultMultiplier,
+		MaxDelay:        defaultMaxDelay,
+		Jitter:          AdditiveJitter,
+		MaxJitter:       defaultMaxJitter,
+		MaxAdvisedDelay: defaultMaxAdvised,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableError: NetworkError,
+	}
+}
+
+// NoRetries returns a policy that sends every request only once. Useful for latency sensitive paths.
+func NoRetries() Policy {
+	return Policy{MaxAttempts: 1}
+}
+
+/*
+NetworkError reports whether err is a transient error sending the request: a
+timeout of the attempt, like the http.Client Timeout, a temporary DNS error, an
+error dialing the API or reading its response, like a refused or reset
+connection, or a connection closed before the response. Other errors, like a TLS
+certificate error, an unsupported scheme or a done context, do not go away by
+themselves. It is the RetryableError of the DefaultPolicy.
+*/
+func NetworkError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if attemptTimeout(err) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) {
+		return dnsError.IsTemporary || dnsError.IsTimeout
+	}
+
+	var urlError *url.Error
+	if errors.As(err, &urlError) && (errors.Is(urlError.Err, io.EOF) || errors.Is(urlError.Err, io.ErrUnexpectedEOF)) {
+		return true
+	}
+
+	var opError *net.OpError
+	return errors.As(err, &opError) && (opError.Op == dialOp || opError.Op == readOp)
+}
+
+// attemptTimeout reports whether err is a timeout of the attempt, and not the
+// deadline of its context. Both report Timeout, and the timeout of the http.Client
+// even wraps context.DeadlineExceeded, so the first timeout found under the errors
+// that only pass on the Timeout of the error they wrap decides.
+func attemptTimeout(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch err.(type) {
+		case *url.Error, *net.OpError:
+			continue
+		}
+		if err == context.DeadlineExceeded {
+			return false
+		}
+		if netError, ok := err.(net.Error); ok && netError.Timeout() {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the policy values make no sense.
+func (p Policy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf(maxAttemptsError, p.MaxAttempts)
+	}
+	if p.BaseDelay < 0 || p.MaxDelay < 0 || p.MaxJitter < 0 || p.MaxAdvisedDelay < 0 {
+		return fmt.Errorf(delayError)
+	}
+	if p.MaxDelay > 0 && p.MaxDelay < p.BaseDelay {
+		return fmt.Errorf(maxDelayError, p.MaxDelay, p.BaseDelay)
+	}
+	if p.MaxAttempts > 1 && p.Multiplier < 1 {
+		return fmt.Errorf(multiplierError, p.Multiplier)
+	}
+	if p.Jitter < NoJitter || p.Jitter > AdditiveJitter {
+		return fmt.Errorf(jitterError, p.Jitter)
+	}
+	return nil
+}
+
+// Delay returns the time to wait before the retry number retry, starting at 1.
+func (p Policy) Delay(retry int) time.Duration {
+	delay := time.Duration(float64(p.BaseDelay) * math.Pow(p.Multiplier, float64(retry)))
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay < 0) {
+		delay = p.MaxDelay
+	}
+
+	switch p.Jitter {
+	case FullJitter:
+		return p.random(delay)
+	case EqualJitter:
+		return delay/2 + p.random(delay/2)
+	case AdditiveJitter:
+		return delay + p.random(p.MaxJitter)
+	}
+	return delay
+}
+
+// RetryableStatusCode reports whether a response with the status code is retried.
+func (p Policy) RetryableStatusCode(statusCode int) bool {
+	for _, retryableStatusCode := range p.RetryableStatusCodes {
+		if statusCode == retryableStatusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Retryable reports whether the error sending a request is retried. The caller
+// must not retry it anyway if the context of the request is done.
+func (p Policy) Retryable(err error) bool {
+	if p.RetryableError == nil || err == nil {
+		return false
+	}
+	return p.RetryableError(err)
+}
+
+func (p Policy) random(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(limit)))
+}
@@ -0,0 +1,140 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var policyTest Policy
+
+type TSPolicy struct{ suite.Suite }
+
+func TestRunTSPolicy(t *testing.T) {
+	suite.Run(t, new(TSPolicy))
+}
+
+func (ts *TSPolicy) BeforeTest(_, _ string) {
+	policyTest = DefaultPolicy()
+	policyTest.Jitter = NoJitter
+}
+
+func (ts *TSPolicy) TestDefaultPolicyIsValid() {
+	ts.NoError(DefaultPolicy().Validate())
+}
+
+func (ts *TSPolicy) TestNoRetriesIsValid() {
+	policy := NoRetries()
+	ts.NoError(policy.Validate())
+	ts.Equal(1, policy.MaxAttempts)
+	ts.False(policy.RetryableStatusCode(http.StatusServiceUnavailable))
+	ts.False(policy.Retryable(fmt.Errorf("fakeError")))
+}
+
+func (ts *TSPolicy) TestInvalidPoliciesReturnError() {
+	invalidPolicies := map[string]Policy{
+		"max attempts": {MaxAttempts: 0},
+		"negative":     {MaxAttempts: 1, BaseDelay: -time.Second},
+		"max delay":    {MaxAttempts: 1, BaseDelay: time.Second, MaxDelay: time.Millisecond},
+		"multiplier":   {MaxAttempts: 2, Multiplier: 0.5},
+		"jitter":       {MaxAttempts: 1, Jitter: Jitter(42)},
+	}
+	for expected, policy := range invalidPolicies {
+		ts.ErrorContains(policy.Validate(), expected)
+	}
+}
+
+func (ts *TSPolicy) TestDelayGrowsExponentially() {
+	ts.Equal(1500*time.Millisecond, policyTest.Delay(1))
+	ts.Equal(2250*time.Millisecond, policyTest.Delay(2))
+	ts.Equal(3375*time.Millisecond, policyTest.Delay(3))
+}
+
+func (ts *TSPolicy) TestDelayIsLimitedByMaxDelay() {
+	policyTest.MaxDelay = 2 * time.Second
+	ts.Equal(2*time.Second, policyTest.Delay(3))
+	ts.Equal(2*time.Second, policyTest.Delay(1000))
+}
+
+func (ts *TSPolicy) TestDelayWithJitterStaysInLimits() {
+	for i := 0; i < 100; i++ {
+		policyTest.Jitter = FullJitter
+		ts.LessOrEqual(policyTest.Delay(1), 1500*time.Millisecond)
+		policyTest.Jitter = EqualJitter
+		ts.GreaterOrEqual(policyTest.Delay(1), 750*time.Millisecond)
+		ts.LessOrEqual(policyTest.Delay(1), 1500*time.Millisecond)
+		policyTest.Jitter = AdditiveJitter
+		ts.GreaterOrEqual(policyTest.Delay(1), 1500*time.Millisecond)
+		ts.Less(policyTest.Delay(1), 1500*time.Millisecond+policyTest.MaxJitter)
+	}
+}
+
+func (ts *TSPolicy) TestRetryableStatusCode() {
+	ts.True(policyTest.RetryableStatusCode(http.StatusTooManyRequests))
+	ts.True(policyTest.RetryableStatusCode(http.StatusGatewayTimeout))
+	ts.False(policyTest.RetryableStatusCode(http.StatusNotFound))
+	ts.False(policyTest.RetryableStatusCode(http.StatusNotImplemented))
+}
+
+func (ts *TSPolicy) TestRetryableError() {
+	refusedError := &url.Error{Op: "Post", URL: "http://fakeaddress", Err: &net.OpError{
+		Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	resetError := &url.Error{Op: "Get", URL: "http://fakeaddress", Err: &net.OpError{
+		Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}}
+	closedError := &url.Error{Op: "Get", URL: "http://fakeaddress", Err: io.EOF}
+	dnsError := &url.Error{Op: "Get", URL: "http://fakeaddress", Err: &net.DNSError{IsTemporary: true}}
+	for _, err := range []error{refusedError, resetError, closedError, dnsError} {
+		ts.True(policyTest.Retryable(err), err)
+	}
+
+	notFoundError := &url.Error{Op: "Get", URL: "http://fakeaddress", Err: &net.DNSError{IsNotFound: true}}
+	contextError := &url.Error{Op: "Get", URL: "http://fakeaddress", Err: &net.OpError{
+		Op: "dial", Net: "tcp", Err: context.DeadlineExceeded}}
+	for _, err := range []error{nil, fmt.Errorf("fakeSigningError"), io.EOF, context.Canceled,
+		fmt.Errorf("wrapped: %w", context.DeadlineExceeded), notFoundError, contextError} {
+		ts.False(policyTest.Retryable(err), err)
+	}
+}
+
+func (ts *TSPolicy) TestClientTimeoutIsRetryableButContextDeadlineIsNot() {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &http.Client{Timeout: 10 * time.Millisecond}
+	_, clientTimeoutError := client.Get(server.URL)
+	ts.Require().ErrorIs(clientTimeoutError, context.DeadlineExceeded)
+	ts.True(policyTest.Retryable(clientTimeoutError))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	ts.Require().NoError(err)
+	_, deadlineError := http.DefaultClient.Do(request)
+	ts.Require().ErrorIs(deadlineError, context.DeadlineExceeded)
+	ts.False(policyTest.Retryable(deadlineError))
+}
+
+func (ts *TSPolicy) TestPermanentTransportErrorsAreNotRetryable() {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	_, certificateError := http.Get(server.URL)
+	ts.Require().Error(certificateError)
+	_, schemeError := http.Get("ftp://api.form3.tech/v1/organisation/accounts")
+	ts.Require().Error(schemeError)
+
+	ts.False(policyTest.Retryable(certificateError))
+	ts.False(policyTest.Retryable(schemeError))
+}
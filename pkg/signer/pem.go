@@ -0,0 +1,99 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+const (
+	emptyKeyIDError  = "signer: key ID cannot be empty"
+	invalidPEMError  = "signer: failed decoding private key: no PEM data found"
+	parseKeyErrorFmt = "signer: failed parsing private key: %v"
+	keyTypeErrorFmt  = "signer: unsupported private key type %T, only RSA and ECDSA keys are supported"
+	readFileErrorFmt = "signer: failed reading private key file: %v"
+)
+
+// PEMSigner signs with a private key held in memory.
+type PEMSigner struct {
+	keyID     string
+	key       crypto.Signer
+	algorithm string
+}
+
+/*
+NewPEMSigner returns a PEMSigner with the RSA or ECDSA private key in PEM format,
+either PKCS#1, SEC 1 or PKCS#8. The keyID is the ID of the public key registered
+in Form3 for the organisation.
+*/
+func NewPEMSigner(keyID string, privateKeyPEM []byte) (*PEMSigner, error) {
+	if keyID == "" {
+		return nil, fmt.Errorf(emptyKeyIDError)
+	}
+
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := &PEMSigner{keyID: keyID, key: key, algorithm: RSAAlgorithm}
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		signer.algorithm = ECDSAAlgorithm
+	}
+	return signer, nil
+}
+
+// NewFileSigner works as NewPEMSigner with the private key read from the file.
+func NewFileSigner(keyID, privateKeyPath string) (*PEMSigner, error) {
+	privateKeyPEM, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf(readFileErrorFmt, err)
+	}
+	return NewPEMSigner(keyID, privateKeyPEM)
+}
+
+func (p *PEMSigner) KeyID() string {
+	return p.keyID
+}
+
+func (p *PEMSigner) Algorithm() string {
+	return p.algorithm
+}
+
+func (p *PEMSigner) Sign(digest []byte) ([]byte, error) {
+	return p.key.Sign(rand.Reader, digest, crypto.SHA256)
+}
+
+func parsePrivateKey(privateKeyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf(invalidPEMError)
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(parseKeyErrorFmt, err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf(keyTypeErrorFmt, key)
+}
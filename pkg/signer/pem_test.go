@@ -0,0 +1,100 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const keyIDTest = "75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8"
+
+var (
+	rsaKeyTest, _   = rsa.GenerateKey(rand.Reader, 2048)
+	ecdsaKeyTest, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	digestTest      = sha256.Sum256([]byte("(request-target): get /v1/organisation/accounts"))
+)
+
+type TSPEMSigner struct{ suite.Suite }
+
+func TestRunTSPEMSigner(t *testing.T) {
+	suite.Run(t, new(TSPEMSigner))
+}
+
+func (ts *TSPEMSigner) TestNewPEMSignerWithEveryPEMFormat() {
+	pkcs8RSA, _ := x509.MarshalPKCS8PrivateKey(rsaKeyTest)
+	pkcs8ECDSA, _ := x509.MarshalPKCS8PrivateKey(ecdsaKeyTest)
+	sec1ECDSA, _ := x509.MarshalECPrivateKey(ecdsaKeyTest)
+	keys := map[string][]byte{
+		RSAAlgorithm + " pkcs1":   pemBlock("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKeyTest)),
+		RSAAlgorithm + " pkcs8":   pemBlock("PRIVATE KEY", pkcs8RSA),
+		ECDSAAlgorithm + " pkcs8": pemBlock("PRIVATE KEY", pkcs8ECDSA),
+		ECDSAAlgorithm + " sec1":  pemBlock("EC PRIVATE KEY", sec1ECDSA),
+	}
+
+	for name, key := range keys {
+		signer, err := NewPEMSigner(keyIDTest, key)
+		ts.NoError(err, name)
+		ts.Equal(keyIDTest, signer.KeyID())
+		ts.Equal(strings.Fields(name)[0], signer.Algorithm(), name)
+		ts.Equal(strings.Fields(name)[0], Algorithm(signer), name)
+	}
+}
+
+func (ts *TSPEMSigner) TestNewPEMSignerWithInvalidValuesReturnsError() {
+	_, err := NewPEMSigner("", rsaPEM())
+	ts.ErrorContains(err, "key ID cannot be empty")
+
+	_, err = NewPEMSigner(keyIDTest, []byte("not a pem"))
+	ts.ErrorContains(err, "no PEM data found")
+
+	_, err = NewPEMSigner(keyIDTest, pemBlock("RSA PRIVATE KEY", []byte("fake key")))
+	ts.ErrorContains(err, "failed parsing private key")
+}
+
+func (ts *TSPEMSigner) TestSignWithRSAKeyIsVerified() {
+	signer, err := NewPEMSigner(keyIDTest, rsaPEM())
+	ts.NoError(err)
+	signature, err := signer.Sign(digestTest[:])
+	ts.NoError(err)
+	ts.NoError(rsa.VerifyPKCS1v15(&rsaKeyTest.PublicKey, crypto.SHA256, digestTest[:], signature))
+}
+
+func (ts *TSPEMSigner) TestSignWithECDSAKeyIsVerified() {
+	sec1ECDSA, _ := x509.MarshalECPrivateKey(ecdsaKeyTest)
+	signer, err := NewPEMSigner(keyIDTest, pemBlock("EC PRIVATE KEY", sec1ECDSA))
+	ts.NoError(err)
+	signature, err := signer.Sign(digestTest[:])
+	ts.NoError(err)
+	ts.True(ecdsa.VerifyASN1(&ecdsaKeyTest.PublicKey, digestTest[:], signature))
+}
+
+func (ts *TSPEMSigner) TestNewFileSignerReadsKeyFromFile() {
+	path := filepath.Join(ts.T().TempDir(), "private.pem")
+	ts.NoError(os.WriteFile(path, rsaPEM(), 0600))
+
+	signer, err := NewFileSigner(keyIDTest, path)
+	ts.NoError(err)
+	ts.Equal(RSAAlgorithm, signer.Algorithm())
+
+	_, err = NewFileSigner(keyIDTest, filepath.Join(ts.T().TempDir(), "missing.pem"))
+	ts.ErrorContains(err, "failed reading private key file")
+}
+
+func rsaPEM() []byte {
+	return pemBlock("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKeyTest))
+}
+
+func pemBlock(blockType string, data []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data})
+}
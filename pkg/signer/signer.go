@@ -0,0 +1,41 @@
+/*
+Package signer has the Signer interface used to sign the requests sent to the
+Form3 API, and its implementations.
+
+Implement Signer to sign with keys held outside the process, like in an HSM or
+a KMS, and set it with form3.WithSigner.
+*/
+package signer
+
+// Signer signs the requests of the key registered in Form3 with the ID KeyID.
+type Signer interface {
+	// KeyID returns the ID of the public key registered in Form3.
+	KeyID() string
+	// Sign returns the signature of the SHA-256 digest of the signing string:
+	// PKCS#1 v1.5 for RSA keys and ASN.1 for ECDSA keys.
+	Sign(digest []byte) ([]byte, error)
+}
+
+/*
+AlgorithmSigner is a Signer that also tells the algorithm of its key, set in the
+algorithm parameter of the signature. The algorithm of the signers that do not
+implement it is HS2019Algorithm, which means the API gets it from the key.
+*/
+type AlgorithmSigner interface {
+	Signer
+	Algorithm() string
+}
+
+const (
+	RSAAlgorithm    = "rsa-sha256"
+	ECDSAAlgorithm  = "ecdsa-sha256"
+	HS2019Algorithm = "hs2019"
+)
+
+// Algorithm returns the algorithm of the signer.
+func Algorithm(s Signer) string {
+	if algorithmSigner, ok := s.(AlgorithmSigner); ok && algorithmSigner.Algorithm() != "" {
+		return algorithmSigner.Algorithm()
+	}
+	return HS2019Algorithm
+}
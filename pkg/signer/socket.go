@@ -0,0 +1,173 @@
+package signer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+/*
+The socket protocol is one JSON object per line. The client sends:
+
+	{"key_id": "<key ID>", "digest": "<base64 digest>"}
+
+and the signing process answers with the signature, or an error:
+
+	{"signature": "<base64 signature>"}
+	{"error": "<error message>"}
+*/
+
+const (
+	defaultSocketTimeout = 5 * time.Second
+
+	socketErrorFmt    = "signer: failed signing through %s %s: %v"
+	remoteErrorFmt    = "signer: signing process returned an error: %s"
+	unknownKeyFmt     = "unknown key ID %s"
+	emptySignatureErr = "empty signature"
+)
+
+type signRequest struct {
+	KeyID  string `json:"key_id"`
+	Digest []byte `json:"digest"`
+}
+
+type signResponse struct {
+	Signature []byte `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+/*
+SocketSigner delegates the signature to an external signing process listening on
+a local socket, so the private key is never in the memory of this process. Every
+signature opens a new connection.
+
+It is the reference implementation to sign with an HSM or a KMS: the signing
+process can be written in any language following the protocol above. Serve
+implements the process side in Go.
+*/
+type SocketSigner struct {
+	keyID     string
+	algorithm string
+	network   string
+	address   string
+	timeout   time.Duration
+}
+
+/*
+NewSocketSigner returns a SocketSigner connecting to the address on the network,
+usually "unix" and the path of the socket. The algorithm is the one of the key
+held by the signing process (RSAAlgorithm or ECDSAAlgorithm), or empty to use
+HS2019Algorithm.
+
+Example: signer.NewSocketSigner(keyID, signer.RSAAlgorithm, "unix", "/run/form3-signer.sock")
+*/
+func NewSocketSigner(keyID, algorithm, network, address string) *SocketSigner {
+	return &SocketSigner{
+		keyID:     keyID,
+		algorithm: algorithm,
+		network:   network,
+		address:   address,
+		timeout:   defaultSocketTimeout,
+	}
+}
+
+func (s *SocketSigner) KeyID() string {
+	return s.keyID
+}
+
+func (s *SocketSigner) Algorithm() string {
+	return s.algorithm
+}
+
+// Sign sends the digest to the signing process and returns its signature. It
+// fails if the whole exchange takes more than 5 seconds.
+func (s *SocketSigner) Sign(digest []byte) ([]byte, error) {
+	signature, err := s.sign(digest)
+	if err != nil {
+		return nil, fmt.Errorf(socketErrorFmt, s.network, s.address, err)
+	}
+	return signature, nil
+}
+
+func (s *SocketSigner) sign(digest []byte) ([]byte, error) {
+	conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return nil, err
+	}
+
+	if err := json.NewEncoder(conn).Encode(signRequest{KeyID: s.keyID, Digest: digest}); err != nil {
+		return nil, err
+	}
+
+	response := signResponse{}
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(remoteErrorFmt, response.Error)
+	}
+	if len(response.Signature) == 0 {
+		return nil, errors.New(emptySignatureErr)
+	}
+	return response.Signature, nil
+}
+
+/*
+Serve answers the sign requests received on the listener with the signer, until
+the listener is closed. It is the signing process side of SocketSigner: wrap the
+HSM or KMS in a Signer and serve it in its own process.
+
+Example:
+
+	listener, err := net.Listen("unix", "/run/form3-signer.sock")
+	...
+	err = signer.Serve(listener, hsmSigner)
+*/
+func Serve(listener net.Listener, signer Signer) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go serveConn(conn, signer)
+	}
+}
+
+func serveConn(conn net.Conn, signer Signer) {
+	defer conn.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(conn))
+	encoder := json.NewEncoder(conn)
+	for {
+		request := signRequest{}
+		if err := decoder.Decode(&request); err != nil {
+			return
+		}
+		if err := encoder.Encode(sign(signer, request)); err != nil {
+			return
+		}
+	}
+}
+
+func sign(signer Signer, request signRequest) signResponse {
+	if request.KeyID != signer.KeyID() {
+		return signResponse{Error: fmt.Sprintf(unknownKeyFmt, request.KeyID)}
+	}
+
+	signature, err := signer.Sign(request.Digest)
+	if err != nil {
+		return signResponse{Error: err.Error()}
+	}
+	return signResponse{Signature: signature}
+}
@@ -0,0 +1,94 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type failingSignerTest struct{}
+
+func (failingSignerTest) KeyID() string { return keyIDTest }
+
+func (failingSignerTest) Sign([]byte) ([]byte, error) { return nil, errors.New("fake hsm error") }
+
+type TSSocketSigner struct {
+	suite.Suite
+	listener   net.Listener
+	socketPath string
+	served     chan error
+}
+
+func TestRunTSSocketSigner(t *testing.T) {
+	suite.Run(t, new(TSSocketSigner))
+}
+
+func (ts *TSSocketSigner) BeforeTest(_, _ string) {
+	ts.socketPath = filepath.Join(ts.T().TempDir(), "signer.sock")
+	listener, err := net.Listen("unix", ts.socketPath)
+	ts.Require().NoError(err)
+	ts.listener = listener
+	ts.served = nil
+}
+
+// AfterTest checks the error of Serve once the listener is closed, so it is not
+// checked from its goroutine after the test ends.
+func (ts *TSSocketSigner) AfterTest(_, _ string) {
+	ts.listener.Close()
+	if ts.served != nil {
+		ts.NoError(<-ts.served)
+	}
+}
+
+func (ts *TSSocketSigner) serve(signer Signer) {
+	served := make(chan error, 1)
+	ts.served = served
+	go func() {
+		served <- Serve(ts.listener, signer)
+	}()
+}
+
+func (ts *TSSocketSigner) TestSignDelegatesToSigningProcess() {
+	pemSigner, err := NewPEMSigner(keyIDTest, rsaPEM())
+	ts.NoError(err)
+	ts.serve(pemSigner)
+
+	socketSigner := NewSocketSigner(keyIDTest, RSAAlgorithm, "unix", ts.socketPath)
+	ts.Equal(keyIDTest, socketSigner.KeyID())
+	ts.Equal(RSAAlgorithm, Algorithm(socketSigner))
+
+	for i := 0; i < 3; i++ {
+		signature, err := socketSigner.Sign(digestTest[:])
+		ts.NoError(err)
+		ts.NoError(rsa.VerifyPKCS1v15(&rsaKeyTest.PublicKey, crypto.SHA256, digestTest[:], signature))
+	}
+}
+
+func (ts *TSSocketSigner) TestSignWithUnknownKeyIDReturnsError() {
+	pemSigner, err := NewPEMSigner(keyIDTest, rsaPEM())
+	ts.NoError(err)
+	ts.serve(pemSigner)
+
+	_, err = NewSocketSigner("fakeKeyID", "", "unix", ts.socketPath).Sign(digestTest[:])
+	ts.ErrorContains(err, "unknown key ID fakeKeyID")
+}
+
+func (ts *TSSocketSigner) TestSignWithSigningProcessErrorReturnsError() {
+	ts.serve(failingSignerTest{})
+
+	socketSigner := NewSocketSigner(keyIDTest, "", "unix", ts.socketPath)
+	ts.Equal(HS2019Algorithm, Algorithm(socketSigner))
+	_, err := socketSigner.Sign(digestTest[:])
+	ts.ErrorContains(err, "fake hsm error")
+}
+
+func (ts *TSSocketSigner) TestSignWithoutSigningProcessReturnsError() {
+	ts.listener.Close()
+	_, err := NewSocketSigner(keyIDTest, "", "unix", ts.socketPath).Sign(digestTest[:])
+	ts.ErrorContains(err, "failed signing through unix")
+}